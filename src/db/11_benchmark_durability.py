@@ -0,0 +1,330 @@
+#!/usr/bin/env python3
+"""Benchmark comparing per-block durability (fsync) strategies.
+
+This script measures block insert throughput under different durability
+strategies, all on the full arkiv bi-temporal schema in WAL mode:
+
+1. full_every_block   - synchronous=FULL, commit every block (fsync per block)
+2. normal_every_block - synchronous=NORMAL, commit every block (fsync at checkpoint)
+3. normal_every_10    - synchronous=NORMAL, commit every 10 blocks
+4. off_every_block    - synchronous=OFF, commit every block (async, OS flushes)
+
+With --crash, each strategy additionally runs in a child process that is
+killed (SIGKILL) mid-run. The parent compares the last block the child
+reported as committed against the blocks found in the database afterwards.
+A process crash keeps the OS page cache, so data loss here is expected to be
+zero for every strategy; losses from power failure need a VM-level test.
+
+Usage:
+    uv run python -m db.11_benchmark_durability                     # All strategies
+    uv run python -m db.11_benchmark_durability off_every_block     # Specific strategies
+    uv run python -m db.11_benchmark_durability --crash             # Include crash test
+"""
+
+import json
+import os
+import random
+import signal
+import sqlite3
+import subprocess
+import sys
+import tempfile
+import time
+from dataclasses import dataclass
+from pathlib import Path
+
+NUM_BLOCKS = 500
+ENTITIES_PER_BLOCK = 100
+PAYLOAD_SIZE = 2 * 1024  # 2KB
+NUM_STR_ATTRS = 5
+NUM_INT_ATTRS = 3
+
+# Crash test: let the child write for this long before killing it
+CRASH_AFTER_SECONDS = 3.0
+
+
+@dataclass
+class DurabilityStrategy:
+    """A synchronous mode combined with a commit interval (in blocks)."""
+    name: str
+    synchronous: str
+    commit_every: int
+
+
+STRATEGIES = {
+    s.name: s for s in [
+        DurabilityStrategy("full_every_block", "FULL", 1),
+        DurabilityStrategy("normal_every_block", "NORMAL", 1),
+        DurabilityStrategy("normal_every_10", "NORMAL", 10),
+        DurabilityStrategy("off_every_block", "OFF", 1),
+    ]
+}
+
+
+@dataclass
+class BenchmarkResult:
+    """Result of a single benchmark run."""
+    strategy: DurabilityStrategy
+    num_blocks: int
+    num_entities: int
+    duration_seconds: float
+
+    @property
+    def blocks_per_second(self) -> float:
+        return self.num_blocks / self.duration_seconds
+
+    @property
+    def entities_per_second(self) -> float:
+        return self.num_entities / self.duration_seconds
+
+
+@dataclass
+class CrashResult:
+    """Outcome of killing a writer mid-run."""
+    strategy: DurabilityStrategy
+    reported_block: int
+    persisted_block: int
+
+    @property
+    def lost_blocks(self) -> int:
+        return max(0, self.reported_block - self.persisted_block)
+
+
+def create_arkiv_schema(conn: sqlite3.Connection) -> None:
+    """Create the full arkiv schema (bi-temporal, 13 indexes)."""
+    conn.execute("""
+        CREATE TABLE string_attributes (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            key TEXT NOT NULL,
+            value TEXT NOT NULL,
+            PRIMARY KEY (entity_key, key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX sa_ekv_idx ON string_attributes (from_block, to_block, key, value)")
+    conn.execute("CREATE INDEX sa_kv_idx ON string_attributes (key, value, from_block DESC, to_block DESC)")
+    conn.execute("CREATE INDEX sa_ek_idx ON string_attributes (from_block, to_block, key)")
+    conn.execute("CREATE INDEX sa_del_idx ON string_attributes (to_block)")
+    conn.execute("CREATE INDEX sa_ekv2_idx ON string_attributes (entity_key, key, from_block DESC)")
+
+    conn.execute("""
+        CREATE TABLE numeric_attributes (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            key TEXT NOT NULL,
+            value INTEGER NOT NULL,
+            PRIMARY KEY (entity_key, key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX na_ekv_idx ON numeric_attributes (from_block, to_block, key, value)")
+    conn.execute("CREATE INDEX na_ek_idx ON numeric_attributes (from_block, to_block, key)")
+    conn.execute("CREATE INDEX na_kv_idx ON numeric_attributes (key, value, from_block DESC, to_block DESC)")
+    conn.execute("CREATE INDEX na_del_idx ON numeric_attributes (to_block)")
+
+    conn.execute("""
+        CREATE TABLE payloads (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            payload BLOB NOT NULL,
+            content_type TEXT NOT NULL DEFAULT '',
+            string_attributes TEXT NOT NULL DEFAULT '{}',
+            numeric_attributes TEXT NOT NULL DEFAULT '{}',
+            PRIMARY KEY (entity_key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX p_ek_idx ON payloads (entity_key, from_block, to_block)")
+    conn.execute("CREATE INDEX p_del_idx ON payloads (to_block)")
+    conn.commit()
+
+
+def open_database(db_path: str, strategy: DurabilityStrategy) -> sqlite3.Connection:
+    """Open a WAL database with the strategy's synchronous mode."""
+    conn = sqlite3.connect(db_path)
+    conn.execute("PRAGMA journal_mode=WAL")
+    conn.execute(f"PRAGMA synchronous={strategy.synchronous}")
+    return conn
+
+
+def insert_block(cursor: sqlite3.Cursor, block_num: int, rng: random.Random) -> None:
+    """Insert one block of entities (5 str + 3 int attrs, 2KB payload each)."""
+    to_block = 999999999
+    for i in range(ENTITIES_PER_BLOCK):
+        entity_key = f"entity_{block_num:06d}_{i:04d}".encode()
+        str_attrs = {f"str_attr_{j}": f"value_{rng.randint(0, 100000)}" for j in range(NUM_STR_ATTRS)}
+        int_attrs = {f"num_attr_{j}": rng.randint(0, 1000000) for j in range(NUM_INT_ATTRS)}
+        payload = rng.randbytes(PAYLOAD_SIZE)
+
+        for key, value in str_attrs.items():
+            cursor.execute(
+                "INSERT INTO string_attributes (entity_key, from_block, to_block, key, value) VALUES (?, ?, ?, ?, ?)",
+                (entity_key, block_num, to_block, key, value),
+            )
+        for key, value in int_attrs.items():
+            cursor.execute(
+                "INSERT INTO numeric_attributes (entity_key, from_block, to_block, key, value) VALUES (?, ?, ?, ?, ?)",
+                (entity_key, block_num, to_block, key, value),
+            )
+        cursor.execute(
+            """INSERT INTO payloads (entity_key, from_block, to_block, payload, content_type,
+               string_attributes, numeric_attributes) VALUES (?, ?, ?, ?, ?, ?, ?)""",
+            (entity_key, block_num, to_block, payload, "application/octet-stream",
+             json.dumps(str_attrs), json.dumps(int_attrs)),
+        )
+
+
+def remove_database(db_path: str) -> None:
+    """Delete a database file and its WAL/SHM companions."""
+    Path(db_path).unlink(missing_ok=True)
+    Path(db_path + "-wal").unlink(missing_ok=True)
+    Path(db_path + "-shm").unlink(missing_ok=True)
+
+
+def run_benchmark(strategy: DurabilityStrategy) -> BenchmarkResult:
+    """Run the throughput benchmark for one strategy."""
+    with tempfile.NamedTemporaryFile(suffix='.db', delete=False) as f:
+        db_path = f.name
+
+    try:
+        conn = open_database(db_path, strategy)
+        create_arkiv_schema(conn)
+        cursor = conn.cursor()
+        rng = random.Random(42)
+
+        start = time.perf_counter()
+        for block_num in range(1, NUM_BLOCKS + 1):
+            insert_block(cursor, block_num, rng)
+            if block_num % strategy.commit_every == 0:
+                conn.commit()
+        conn.commit()
+        end = time.perf_counter()
+        conn.close()
+
+        return BenchmarkResult(
+            strategy=strategy,
+            num_blocks=NUM_BLOCKS,
+            num_entities=NUM_BLOCKS * ENTITIES_PER_BLOCK,
+            duration_seconds=end - start,
+        )
+    finally:
+        remove_database(db_path)
+
+
+def run_crash_child(strategy_name: str, db_path: str) -> None:
+    """Write blocks forever, printing each block number once committed."""
+    strategy = STRATEGIES[strategy_name]
+    conn = open_database(db_path, strategy)
+    create_arkiv_schema(conn)
+    cursor = conn.cursor()
+    rng = random.Random(42)
+
+    block_num = 0
+    while True:
+        block_num += 1
+        insert_block(cursor, block_num, rng)
+        if block_num % strategy.commit_every == 0:
+            conn.commit()
+            print(block_num, flush=True)
+
+
+def run_crash_test(strategy: DurabilityStrategy) -> CrashResult:
+    """Kill a writer child mid-run and compare reported vs persisted blocks."""
+    with tempfile.NamedTemporaryFile(suffix='.db', delete=False) as f:
+        db_path = f.name
+    Path(db_path).unlink()
+
+    try:
+        child = subprocess.Popen(
+            [sys.executable, "-m", "db.11_benchmark_durability", "--child", strategy.name, db_path],
+            stdout=subprocess.PIPE,
+            text=True,
+            env={**os.environ, "PYTHONPATH": str(Path(__file__).parent.parent)},
+        )
+        time.sleep(CRASH_AFTER_SECONDS)
+        child.send_signal(signal.SIGKILL)
+        output, _ = child.communicate()
+
+        reported = output.split()
+        reported_block = int(reported[-1]) if reported else 0
+
+        conn = sqlite3.connect(db_path)
+        row = conn.execute("SELECT MAX(from_block) FROM payloads").fetchone()
+        conn.close()
+        persisted_block = row[0] if row and row[0] is not None else 0
+
+        return CrashResult(
+            strategy=strategy,
+            reported_block=reported_block,
+            persisted_block=persisted_block,
+        )
+    finally:
+        remove_database(db_path)
+
+
+def main():
+    """Run durability strategy benchmarks."""
+    args = sys.argv[1:]
+
+    if args[:1] == ["--child"]:
+        run_crash_child(args[1], args[2])
+        return
+
+    crash = "--crash" in args
+    names = [a for a in args if a != "--crash"]
+    unknown = [n for n in names if n not in STRATEGIES]
+    if unknown:
+        print(f"Unknown strategies: {', '.join(unknown)}")
+        print(f"Available: {', '.join(STRATEGIES)}")
+        print("Usage: python -m db.11_benchmark_durability [--crash] [strategy ...]")
+        sys.exit(1)
+    strategies = [STRATEGIES[n] for n in names] if names else list(STRATEGIES.values())
+
+    print("SQLite Durability Strategy Benchmark")
+    print("=" * 75)
+    print(f"Schema: Full arkiv bi-temporal (13 indexes), WAL mode")
+    print(f"Blocks: {NUM_BLOCKS:,} x {ENTITIES_PER_BLOCK} entities "
+          f"({NUM_STR_ATTRS} str + {NUM_INT_ATTRS} int attrs, {PAYLOAD_SIZE // 1024}KB payload)")
+    print()
+
+    print("Running benchmarks...")
+    print("-" * 75)
+
+    results = []
+    for strategy in strategies:
+        print(f"  {strategy.name}...", end=" ", flush=True)
+        result = run_benchmark(strategy)
+        results.append(result)
+        print(f"{result.duration_seconds:.1f}s ({result.blocks_per_second:,.1f} blocks/s)")
+
+    print()
+    print("=" * 75)
+    print(f"{'Strategy':<22} {'synchronous':<12} {'Commit':<8} {'Time (s)':<10} {'Blocks/s':<10} {'Speedup':<8}")
+    print("=" * 75)
+
+    baseline = results[0].duration_seconds
+    for r in results:
+        speedup = baseline / r.duration_seconds
+        print(f"{r.strategy.name:<22} {r.strategy.synchronous:<12} {r.strategy.commit_every:<8} "
+              f"{r.duration_seconds:<10.2f} {r.blocks_per_second:<10.1f} {speedup:.2f}x")
+
+    print("=" * 75)
+
+    if crash:
+        print()
+        print(f"Crash test (SIGKILL after {CRASH_AFTER_SECONDS:.0f}s)...")
+        print("-" * 75)
+        print(f"{'Strategy':<22} {'Reported':<12} {'Persisted':<12} {'Lost blocks':<12}")
+        for strategy in strategies:
+            crash_result = run_crash_test(strategy)
+            print(f"{strategy.name:<22} {crash_result.reported_block:<12} "
+                  f"{crash_result.persisted_block:<12} {crash_result.lost_blocks:<12}")
+        print("-" * 75)
+        print("Note: blocks written after the last commit are expected to be missing;")
+        print("'Lost blocks' only counts blocks the writer had reported as committed.")
+    print()
+
+
+if __name__ == "__main__":
+    main()
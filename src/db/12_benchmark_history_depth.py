@@ -0,0 +1,264 @@
+#!/usr/bin/env python3
+"""Benchmark read latency at varying historical depths (multi-version reads).
+
+This script updates a fixed set of keys in every block, so each key builds a
+version chain one version per block, then reads the same keys at the head
+block and at increasing depths behind it (head, -10, -100, -1000 blocks).
+
+Two read patterns are measured at each depth, both on the full arkiv
+bi-temporal schema:
+
+1. point_by_key - all attributes + payload of one entity as of the block
+2. attr_filter  - keys whose 'status' attribute has a given value as of the block
+
+Usage:
+    uv run python -m db.12_benchmark_history_depth                  # 100 keys, 1000 blocks
+    uv run python -m db.12_benchmark_history_depth 500 2000         # <num_keys> <num_blocks>
+"""
+
+import json
+import random
+import sqlite3
+import sys
+import tempfile
+import time
+from dataclasses import dataclass
+from pathlib import Path
+
+NUM_KEYS = 100
+NUM_BLOCKS = 1000
+PAYLOAD_SIZE = 512
+NUM_STATUSES = 5
+MAX_BLOCK = 9223372036854775807  # Open-ended version
+
+DEPTHS = [0, 10, 100, 1000]
+QUERIES_PER_DEPTH = 500
+
+
+@dataclass
+class DepthResult:
+    """Latencies (ms) of one read pattern at one historical depth."""
+    pattern: str
+    depth: int
+    latencies_ms: list[float]
+    avg_rows: float
+
+    def percentile(self, p: float) -> float:
+        ordered = sorted(self.latencies_ms)
+        return ordered[min(len(ordered) - 1, int(len(ordered) * p))]
+
+    @property
+    def avg(self) -> float:
+        return sum(self.latencies_ms) / len(self.latencies_ms)
+
+
+def create_arkiv_schema(conn: sqlite3.Connection) -> None:
+    """Create the full arkiv schema (bi-temporal, 13 indexes)."""
+    conn.execute("""
+        CREATE TABLE string_attributes (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            key TEXT NOT NULL,
+            value TEXT NOT NULL,
+            PRIMARY KEY (entity_key, key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX sa_ekv_idx ON string_attributes (from_block, to_block, key, value)")
+    conn.execute("CREATE INDEX sa_kv_idx ON string_attributes (key, value, from_block DESC, to_block DESC)")
+    conn.execute("CREATE INDEX sa_ek_idx ON string_attributes (from_block, to_block, key)")
+    conn.execute("CREATE INDEX sa_del_idx ON string_attributes (to_block)")
+    conn.execute("CREATE INDEX sa_ekv2_idx ON string_attributes (entity_key, key, from_block DESC)")
+
+    conn.execute("""
+        CREATE TABLE numeric_attributes (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            key TEXT NOT NULL,
+            value INTEGER NOT NULL,
+            PRIMARY KEY (entity_key, key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX na_ekv_idx ON numeric_attributes (from_block, to_block, key, value)")
+    conn.execute("CREATE INDEX na_ek_idx ON numeric_attributes (from_block, to_block, key)")
+    conn.execute("CREATE INDEX na_kv_idx ON numeric_attributes (key, value, from_block DESC, to_block DESC)")
+    conn.execute("CREATE INDEX na_del_idx ON numeric_attributes (to_block)")
+
+    conn.execute("""
+        CREATE TABLE payloads (
+            entity_key BLOB NOT NULL,
+            from_block INTEGER NOT NULL,
+            to_block INTEGER NOT NULL,
+            payload BLOB NOT NULL,
+            content_type TEXT NOT NULL DEFAULT '',
+            string_attributes TEXT NOT NULL DEFAULT '{}',
+            numeric_attributes TEXT NOT NULL DEFAULT '{}',
+            PRIMARY KEY (entity_key, from_block)
+        )
+    """)
+    conn.execute("CREATE INDEX p_ek_idx ON payloads (entity_key, from_block, to_block)")
+    conn.execute("CREATE INDEX p_del_idx ON payloads (to_block)")
+    conn.commit()
+
+
+def write_version(cursor: sqlite3.Cursor, entity_key: bytes, block_num: int, rng: random.Random) -> None:
+    """Close the entity's open version (if any) and insert a new one at block_num."""
+    for table in ("string_attributes", "numeric_attributes", "payloads"):
+        cursor.execute(
+            f"UPDATE {table} SET to_block = ? WHERE entity_key = ? AND to_block = ?",
+            (block_num, entity_key, MAX_BLOCK),
+        )
+
+    str_attrs = {
+        "status": f"status_{rng.randint(0, NUM_STATUSES - 1)}",
+        "owner": f"owner_{rng.randint(0, 9)}",
+    }
+    int_attrs = {"counter": block_num, "score": rng.randint(0, 1000000)}
+
+    for key, value in str_attrs.items():
+        cursor.execute(
+            "INSERT INTO string_attributes (entity_key, from_block, to_block, key, value) VALUES (?, ?, ?, ?, ?)",
+            (entity_key, block_num, MAX_BLOCK, key, value),
+        )
+    for key, value in int_attrs.items():
+        cursor.execute(
+            "INSERT INTO numeric_attributes (entity_key, from_block, to_block, key, value) VALUES (?, ?, ?, ?, ?)",
+            (entity_key, block_num, MAX_BLOCK, key, value),
+        )
+    cursor.execute(
+        """INSERT INTO payloads (entity_key, from_block, to_block, payload, content_type,
+           string_attributes, numeric_attributes) VALUES (?, ?, ?, ?, ?, ?, ?)""",
+        (entity_key, block_num, MAX_BLOCK, rng.randbytes(PAYLOAD_SIZE), "application/octet-stream",
+         json.dumps(str_attrs), json.dumps(int_attrs)),
+    )
+
+
+def build_history(conn: sqlite3.Connection, keys: list[bytes], num_blocks: int) -> None:
+    """Update every key once per block, committing per block."""
+    cursor = conn.cursor()
+    rng = random.Random(42)
+    for block_num in range(1, num_blocks + 1):
+        for entity_key in keys:
+            write_version(cursor, entity_key, block_num, rng)
+        conn.commit()
+        if block_num % 100 == 0:
+            print(f"    block {block_num:,}/{num_blocks:,}", flush=True)
+
+
+def read_point_by_key(cursor: sqlite3.Cursor, entity_key: bytes, block: int) -> int:
+    """All attributes and the payload of one entity as of block; returns row count."""
+    cursor.execute(
+        "SELECT key, value FROM string_attributes WHERE entity_key = ? AND from_block <= ? AND to_block > ?",
+        (entity_key, block, block),
+    )
+    rows = len(cursor.fetchall())
+    cursor.execute(
+        "SELECT key, value FROM numeric_attributes WHERE entity_key = ? AND from_block <= ? AND to_block > ?",
+        (entity_key, block, block),
+    )
+    rows += len(cursor.fetchall())
+    cursor.execute(
+        "SELECT payload FROM payloads WHERE entity_key = ? AND from_block <= ? AND to_block > ?",
+        (entity_key, block, block),
+    )
+    rows += len(cursor.fetchall())
+    return rows
+
+
+def read_attr_filter(cursor: sqlite3.Cursor, status: str, block: int) -> int:
+    """Keys with the given status as of block; returns row count."""
+    cursor.execute(
+        """SELECT entity_key FROM string_attributes
+           WHERE key = 'status' AND value = ? AND from_block <= ? AND to_block > ?""",
+        (status, block, block),
+    )
+    return len(cursor.fetchall())
+
+
+def measure_depth(
+    conn: sqlite3.Connection,
+    keys: list[bytes],
+    head_block: int,
+    depth: int,
+) -> list[DepthResult]:
+    """Run both read patterns at head_block - depth."""
+    cursor = conn.cursor()
+    rng = random.Random(depth)
+    block = head_block - depth
+
+    results = []
+    for pattern in ("point_by_key", "attr_filter"):
+        latencies = []
+        rows = 0
+        for _ in range(QUERIES_PER_DEPTH):
+            start = time.perf_counter()
+            if pattern == "point_by_key":
+                rows += read_point_by_key(cursor, rng.choice(keys), block)
+            else:
+                rows += read_attr_filter(cursor, f"status_{rng.randint(0, NUM_STATUSES - 1)}", block)
+            latencies.append((time.perf_counter() - start) * 1000)
+        results.append(DepthResult(pattern, depth, latencies, rows / QUERIES_PER_DEPTH))
+    return results
+
+
+def main():
+    """Build a version history and measure reads at each depth."""
+    args = sys.argv[1:]
+    if len(args) not in (0, 2):
+        print("Usage: python -m db.12_benchmark_history_depth [<num_keys> <num_blocks>]")
+        sys.exit(1)
+    num_keys, num_blocks = (int(args[0]), int(args[1])) if args else (NUM_KEYS, NUM_BLOCKS)
+
+    # Depths beyond the history fall back to the first block
+    depths = sorted({min(d, num_blocks - 1) for d in DEPTHS})
+
+    print("SQLite Multi-Version Read Benchmark")
+    print("=" * 80)
+    print(f"Schema: Full arkiv bi-temporal (13 indexes)")
+    print(f"History: {num_keys:,} keys x {num_blocks:,} blocks (one version per key per block)")
+    print(f"Queries: {QUERIES_PER_DEPTH} per pattern per depth, depths {depths}")
+    print()
+
+    with tempfile.NamedTemporaryFile(suffix='.db', delete=False) as f:
+        db_path = f.name
+
+    try:
+        conn = sqlite3.connect(db_path)
+        conn.execute("PRAGMA journal_mode=WAL")
+        conn.execute("PRAGMA synchronous=NORMAL")
+        create_arkiv_schema(conn)
+
+        keys = [f"entity_{i:06d}".encode() for i in range(num_keys)]
+        print("Building version history...")
+        start = time.perf_counter()
+        build_history(conn, keys, num_blocks)
+        print(f"  done in {time.perf_counter() - start:.1f}s")
+        print()
+
+        results = []
+        for depth in depths:
+            results.extend(measure_depth(conn, keys, num_blocks, depth))
+        conn.close()
+    finally:
+        Path(db_path).unlink(missing_ok=True)
+        Path(db_path + "-wal").unlink(missing_ok=True)
+        Path(db_path + "-shm").unlink(missing_ok=True)
+
+    print("=" * 80)
+    print(f"{'Pattern':<14} {'Depth':>6} {'Rows':>7} {'p50 (ms)':>10} {'p95 (ms)':>10} "
+          f"{'avg (ms)':>10} {'vs head':>8}")
+    print("=" * 80)
+    for pattern in ("point_by_key", "attr_filter"):
+        pattern_results = [r for r in results if r.pattern == pattern]
+        head_p50 = pattern_results[0].percentile(0.50)
+        for r in pattern_results:
+            ratio = r.percentile(0.50) / head_p50 if head_p50 > 0 else 0
+            print(f"{r.pattern:<14} {-r.depth:>6} {r.avg_rows:>7.1f} {r.percentile(0.50):>10.3f} "
+                  f"{r.percentile(0.95):>10.3f} {r.avg:>10.3f} {ratio:>7.2f}x")
+        print("-" * 80)
+    print()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,289 @@
+"""
+Correlate per-block apply time with block features from an append_dc_data.py --block-csv file.
+
+Fits each feature (payload bytes, attribute counts, update/delete counts, DB size)
+against the block apply time with a simple linear regression, then fits all of them
+together, and ranks the features by how much of the latency variance they explain.
+
+Usage:
+    uv run python -m src.db.analyze_block_csv data/blocks.csv
+    uv run python -m src.db.analyze_block_csv data/blocks.csv --testname dc_blocks --target write
+"""
+
+import argparse
+import csv
+import json
+import statistics
+import sys
+
+
+# =============================================================================
+# Configuration
+# =============================================================================
+
+# Block features (CSV columns) considered as explanatory variables
+FEATURES = [
+    "num_entities",
+    "num_updates",
+    "num_deletes",
+    "num_string_attrs",
+    "num_numeric_attrs",
+    "payload_kb",
+    "db_size_kb",
+]
+
+# Target latencies: name -> CSV columns summed per block
+TARGETS = {
+    "apply": ["write_time_ms", "commit_time_ms"],
+    "write": ["write_time_ms"],
+    "commit": ["commit_time_ms"],
+}
+
+# Features correlated above this with an already selected feature are left out of
+# the multiple regression (e.g. attribute counts that scale with num_entities)
+COLLINEARITY_LIMIT = 0.98
+
+# Pivots below this fraction of the largest diagonal entry of XᵀX make the normal
+# equations singular (a feature is a linear combination of others)
+SINGULAR_TOLERANCE = 1e-9
+
+
+# =============================================================================
+# Regression
+# =============================================================================
+
+def simple_fit(x: list[float], y: list[float]) -> dict:
+    """Least-squares fit y = intercept + slope * x with Pearson r and R²."""
+    r = statistics.correlation(x, y)
+    slope, intercept = statistics.linear_regression(x, y)
+    return {"r": r, "r2": r * r, "slope": slope, "intercept": intercept}
+
+
+def solve(matrix: list[list[float]], rhs: list[float]) -> list[float]:
+    """
+    Solve a linear system by Gaussian elimination with partial pivoting.
+
+    Raises ValueError if the matrix is (near-)singular.
+    """
+    n = len(rhs)
+    a = [row[:] + [value] for row, value in zip(matrix, rhs)]
+    tolerance = SINGULAR_TOLERANCE * max((abs(matrix[i][i]) for i in range(n)), default=0.0)
+    for col in range(n):
+        pivot = max(range(col, n), key=lambda row: abs(a[row][col]))
+        if abs(a[pivot][col]) <= tolerance:
+            raise ValueError(f"singular system (column {col})")
+        a[col], a[pivot] = a[pivot], a[col]
+        for row in range(col + 1, n):
+            factor = a[row][col] / a[col][col]
+            for k in range(col, n + 1):
+                a[row][k] -= factor * a[col][k]
+    result = [0.0] * n
+    for row in range(n - 1, -1, -1):
+        result[row] = (a[row][n] - sum(a[row][k] * result[k] for k in range(row + 1, n))) / a[row][row]
+    return result
+
+
+def multiple_fit(columns: dict[str, list[float]], y: list[float]) -> dict:
+    """
+    Least-squares fit of y on all columns, on standardized values.
+
+    Standardized coefficients are comparable across features: the change in y (in
+    standard deviations) per standard deviation of the feature.
+    """
+    names = list(columns)
+    z = {
+        name: [(v - statistics.fmean(values)) / statistics.pstdev(values) for v in values]
+        for name, values in columns.items()
+    }
+    y_mean = statistics.fmean(y)
+    y_std = statistics.pstdev(y)
+    zy = [(v - y_mean) / y_std for v in y]
+
+    # Normal equations on standardized data (no intercept needed)
+    xtx = [[sum(a * b for a, b in zip(z[i], z[j])) for j in names] for i in names]
+    xty = [sum(a * b for a, b in zip(z[i], zy)) for i in names]
+    beta = solve(xtx, xty)
+
+    predicted = [sum(beta[k] * z[name][row] for k, name in enumerate(names)) for row in range(len(y))]
+    ss_res = sum((actual - pred) ** 2 for actual, pred in zip(zy, predicted))
+    ss_tot = sum(v * v for v in zy)
+    return {
+        "r2": 1 - ss_res / ss_tot,
+        "std_coef": dict(zip(names, beta)),
+    }
+
+
+# =============================================================================
+# Analysis
+# =============================================================================
+
+def load_blocks(csv_path: str, testname: str | None) -> list[dict]:
+    """Read block records, optionally filtered by testname."""
+    with open(csv_path, newline="") as f:
+        rows = list(csv.DictReader(f))
+    if testname:
+        rows = [row for row in rows if row["testname"] == testname]
+    return rows
+
+
+def target_values(rows: list[dict], target: str) -> list[float]:
+    """Per-block latency in ms for the given target."""
+    return [sum(float(row[col]) for col in TARGETS[target]) for row in rows]
+
+
+def analyze(rows: list[dict], target: str) -> dict:
+    """Fit the target latency against each feature and against all features."""
+    y = target_values(rows, target)
+
+    columns = {name: [float(row[name]) for row in rows] for name in FEATURES}
+    constant = [name for name, values in columns.items() if len(set(values)) < 2]
+    varying = {name: values for name, values in columns.items() if name not in constant}
+
+    single = {name: simple_fit(values, y) for name, values in varying.items()}
+    ranked = sorted(single, key=lambda name: single[name]["r2"], reverse=True)
+
+    # Build the multiple regression from the best single factors, skipping near-duplicates
+    selected: list[str] = []
+    collinear: dict[str, str] = {}
+    for name in ranked:
+        duplicate = next(
+            (other for other in selected
+             if abs(statistics.correlation(varying[name], varying[other])) > COLLINEARITY_LIMIT),
+            None,
+        )
+        if duplicate:
+            collinear[name] = duplicate
+        else:
+            selected.append(name)
+
+    # Drop the weakest features while the rest are still linearly dependent
+    dependent: list[str] = []
+    multiple = None
+    while selected and multiple is None:
+        try:
+            multiple = multiple_fit({name: varying[name] for name in selected}, y)
+        except ValueError:
+            dependent.insert(0, selected.pop())
+
+    # A feature's unique contribution: R² lost when it is dropped from the multiple fit
+    best = None
+    if multiple:
+        multiple["r2_loss"] = {
+            name: multiple["r2"] - (
+                multiple_fit({other: varying[other] for other in selected if other != name}, y)["r2"]
+                if len(selected) > 1 else 0.0
+            )
+            for name in selected
+        }
+        best = max(selected, key=lambda name: multiple["r2_loss"][name])
+
+    return {
+        "blocks": len(rows),
+        "target": target,
+        "target_avg_ms": statistics.fmean(y),
+        "target_stdev_ms": statistics.pstdev(y),
+        "single": {name: single[name] for name in ranked},
+        "multiple": multiple,
+        "collinear": collinear,
+        "dependent": dependent,
+        "constant": constant,
+        "best": best,
+    }
+
+
+def print_report(csv_path: str, testname: str | None, result: dict) -> None:
+    """Print the correlation report."""
+    print("=" * 70)
+    print("Block Metric Correlation Report")
+    print("=" * 70)
+    print(f"CSV:        {csv_path}")
+    print(f"Testname:   {testname or '(all)'}")
+    print(f"Blocks:     {result['blocks']:,}")
+    print(f"Target:     {result['target']} time "
+          f"(avg {result['target_avg_ms']:.3f}ms, stdev {result['target_stdev_ms']:.3f}ms)")
+    print()
+
+    print("Single-factor fits (ranked by R²):")
+    print(f"  {'Feature':<20} {'r':>7} {'R²':>7} {'ms per unit':>14}")
+    for name, fit in result["single"].items():
+        print(f"  {name:<20} {fit['r']:>7.3f} {fit['r2']:>7.3f} {fit['slope']:>14.6f}")
+    if result["constant"]:
+        print(f"  Constant (not fitted): {', '.join(result['constant'])}")
+    print()
+
+    multiple = result["multiple"]
+    if multiple:
+        print(f"Multiple regression: R² = {multiple['r2']:.3f} "
+              f"({multiple['r2']:.0%} of the variance explained)")
+        print(f"  {'Feature':<20} {'std. coef':>10} {'R² loss':>8}")
+        for name, coef in sorted(multiple["std_coef"].items(), key=lambda item: -multiple["r2_loss"][item[0]]):
+            print(f"  {name:<20} {coef:>10.3f} {multiple['r2_loss'][name]:>8.3f}")
+        for name, other in result["collinear"].items():
+            print(f"  {name:<20} {'(collinear with ' + other + ')':>10}")
+        for name in result["dependent"]:
+            print(f"  {name:<20} (linear combination of the features above)")
+        print()
+
+        best = result["best"]
+        print(f"Best explanatory factor: {best} "
+              f"(R² loss when dropped = {multiple['r2_loss'][best]:.3f}, "
+              f"single-factor R² = {result['single'][best]['r2']:.3f})")
+    print()
+
+
+# =============================================================================
+# Main Entry Point
+# =============================================================================
+
+def main():
+    parser = argparse.ArgumentParser(
+        description="Correlate block apply time with block features from a --block-csv file"
+    )
+    parser.add_argument(
+        "csv",
+        type=str,
+        help="Per-block CSV written by append_dc_data.py --block-csv"
+    )
+    parser.add_argument(
+        "--testname",
+        type=str,
+        default=None,
+        help="Only analyze records with this testname (default: all records)"
+    )
+    parser.add_argument(
+        "--target",
+        choices=list(TARGETS),
+        default="apply",
+        help="Latency to explain: apply (write + commit), write or commit (default: apply)"
+    )
+    parser.add_argument(
+        "--json",
+        action="store_true",
+        help="Output as JSON instead of formatted text"
+    )
+
+    args = parser.parse_args()
+
+    try:
+        rows = load_blocks(args.csv, args.testname)
+    except FileNotFoundError as e:
+        print(f"Error: {e}", file=sys.stderr)
+        sys.exit(1)
+    if len(rows) < 3:
+        print(f"Error: need at least 3 blocks, found {len(rows)}", file=sys.stderr)
+        sys.exit(1)
+
+    if len(set(target_values(rows, args.target))) < 2:
+        print(f"Error: {args.target} time is constant, nothing to explain", file=sys.stderr)
+        sys.exit(1)
+
+    result = analyze(rows, args.target)
+
+    if args.json:
+        print(json.dumps(result, indent=2))
+    else:
+        print_report(args.csv, args.testname, result)
+
+
+if __name__ == "__main__":
+    main()
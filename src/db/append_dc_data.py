@@ -28,16 +28,33 @@ Usage:
 """
 
 import argparse
+import csv
+import gzip
+import hashlib
+import json
+import lzma
 import os
 import random
+import re
 import secrets
 import shutil
 import sqlite3
+import sys
 import time
 import uuid
-from dataclasses import dataclass
+import zlib
+from dataclasses import dataclass, field, replace
 from datetime import datetime
-from typing import Iterator
+from typing import Any, Iterator, TextIO
+
+# zstd is optional: in the standard library from Python 3.14, else the zstandard package
+try:
+    from compression import zstd
+except ImportError:
+    try:
+        import zstandard as zstd
+    except ImportError:
+        zstd = None
 
 
 # =============================================================================
@@ -56,6 +73,45 @@ SYSTEM_NUMERIC_ATTRS = ["$createdAtBlock", "$expiration", "$opIndex", "$sequence
 DEFAULT_NODE_UPDATES_PER_BLOCK = 60
 DEFAULT_WORKLOAD_UPDATES_PER_BLOCK = 600
 
+# Payload content profiles (--payload-profile)
+PAYLOAD_PROFILES = ["random", "zero", "json", "repeat"]
+
+# Payload compression codecs (--compress); zstd only when a zstd module is available
+COMPRESSION_CODECS = ["none", "gzip", "zlib", "lzma"] + (["zstd"] if zstd else [])
+
+# content_type of stored payloads; compressed payloads get "+<codec>" appended
+PAYLOAD_CONTENT_TYPE = "application/octet-stream"
+
+# SQLite settings that can be set with --pragma NAME=VALUE
+TUNABLE_PRAGMAS = ["journal_mode", "synchronous", "cache_size", "mmap_size", "page_size"]
+
+# Per-block CSV columns (--block-csv)
+BLOCK_CSV_HEADER = [
+    "testname", "block_nr", "num_entities", "num_updates", "num_deletes", "num_string_attrs",
+    "num_numeric_attrs", "payload_kb", "build_time_ms", "write_time_ms", "create_time_ms",
+    "update_time_ms", "delete_time_ms", "commit_time_ms", "db_size_kb", "stored_payload_kb",
+    "compress_time_ms", "fingerprint", "pragmas",
+]
+
+# Row inserts into the entity tables, and the table each one writes (for per-table counts)
+STRING_ATTR_INSERT_SQL = """INSERT INTO string_attributes 
+               (entity_key, from_block, to_block, key, value) 
+               VALUES (?, ?, ?, ?, ?)"""
+NUMERIC_ATTR_INSERT_SQL = """INSERT INTO numeric_attributes 
+               (entity_key, from_block, to_block, key, value) 
+               VALUES (?, ?, ?, ?, ?)"""
+PAYLOAD_INSERT_SQL = """INSERT INTO payloads 
+           (entity_key, from_block, to_block, payload, content_type, string_attributes, numeric_attributes) 
+           VALUES (?, ?, ?, ?, ?, ?, ?)"""
+INSERT_TABLES = {
+    STRING_ATTR_INSERT_SQL: "string_attributes",
+    NUMERIC_ATTR_INSERT_SQL: "numeric_attributes",
+    PAYLOAD_INSERT_SQL: "payloads",
+}
+
+# Bit widths available for extra numeric attributes (value range per width)
+NUMERIC_BIT_WIDTHS = [8, 16, 32, 64]
+
 
 @dataclass
 class NodeEntity:
@@ -76,6 +132,8 @@ class NodeEntity:
     tx_index: int = 0
     op_index: int = 0
     sequence: int = 0
+    extra_numeric: dict[str, int] = field(default_factory=dict)
+    content_type: str = PAYLOAD_CONTENT_TYPE
 
 
 @dataclass
@@ -97,6 +155,9 @@ class WorkloadEntity:
     tx_index: int = 0
     op_index: int = 0
     sequence: int = 0
+    extra_numeric: dict[str, int] = field(default_factory=dict)
+    content_type: str = PAYLOAD_CONTENT_TYPE
+    created_block: int | None = None  # Block of the first version (None: block)
 
 
 # =============================================================================
@@ -254,10 +315,81 @@ def sample_from_distribution(rng: random.Random, dist: list[tuple[any, float]])
     return dist[-1][0]  # Fallback to last value
 
 
+def numeric_attr_range(bits: int) -> tuple[int, int]:
+    """Value range (min, max) for an unsigned attribute of the given bit width.
+    
+    64-bit values are capped at 2^63 - 1 since SQLite INTEGER is signed.
+    """
+    return (0, min(2**bits - 1, 2**63 - 1))
+
+
+def numeric_attr_name(bits: int, index: int) -> str:
+    """Name of the index-th extra numeric attribute with the given bit width."""
+    return f"u{bits}_{index}"
+
+
+def make_extra_numeric_attrs(
+    rng: random.Random,
+    bit_widths: list[int],
+    attrs_per_width: int,
+) -> dict[str, int]:
+    """Sample extra numeric attributes uniformly over each bit width's range."""
+    attrs = {}
+    for bits in bit_widths:
+        min_val, max_val = numeric_attr_range(bits)
+        for i in range(1, attrs_per_width + 1):
+            attrs[numeric_attr_name(bits, i)] = rng.randint(min_val, max_val)
+    return attrs
+
+
 # =============================================================================
 # ID Generation (deterministic)
 # =============================================================================
 
+def make_payload(rng: random.Random, payload_size: int, profile: str = "random") -> bytes:
+    """Generate a payload of payload_size bytes with the given content profile.
+    
+    - random:  incompressible random bytes
+    - zero:    all zero bytes
+    - json:    JSON-like text records
+    - repeat:  a random 64-byte pattern repeated
+    """
+    if profile == "zero":
+        return bytes(payload_size)
+    if profile == "json":
+        words = ["alpha", "beta", "gamma", "delta", "compute", "storage", "region", "status"]
+        records = []
+        size = 0
+        while size < payload_size:
+            record = (f'{{"id": {rng.randint(0, 999999)}, "name": "{rng.choice(words)}", '
+                      f'"value": {rng.random():.6f}, "tags": ["{rng.choice(words)}", "{rng.choice(words)}"]}}')
+            records.append(record)
+            size += len(record) + 2
+        return ("[" + ", ".join(records) + "]").encode()[:payload_size]
+    if profile == "repeat":
+        pattern = bytes(rng.getrandbits(8) for _ in range(64))
+        return (pattern * (payload_size // 64 + 1))[:payload_size]
+    return bytes(rng.getrandbits(8) for _ in range(payload_size))
+
+
+def compress_payload(payload: bytes, codec: str) -> bytes:
+    """Compress a payload with the given codec ("none" returns it unchanged)."""
+    if codec == "gzip":
+        return gzip.compress(payload, mtime=0)
+    if codec == "zlib":
+        return zlib.compress(payload)
+    if codec == "lzma":
+        return lzma.compress(payload)
+    if codec == "zstd":
+        return zstd.compress(payload)
+    return payload
+
+
+def payload_content_type(codec: str) -> str:
+    """content_type recorded for payloads stored with the given codec."""
+    return PAYLOAD_CONTENT_TYPE if codec == "none" else f"{PAYLOAD_CONTENT_TYPE}+{codec}"
+
+
 def make_dc_id(dc_num: int) -> str:
     """Generate data center ID: dc_01, dc_02, ..."""
     return f"dc_{dc_num:02d}"
@@ -304,6 +436,7 @@ def create_node(
     block: int,
     seed: int,
     status: str | None = None,
+    payload_profile: str = "random",
 ) -> NodeEntity:
     """Create a single Node entity with randomized attributes.
     
@@ -328,8 +461,8 @@ def create_node(
     avail_hours = sample_from_distribution(rng, get_avail_hours_distribution())
     ttl_blocks = sample_ttl_blocks(rng)
 
-    # Generate random payload
-    payload = bytes(rng.getrandbits(8) for _ in range(payload_size))
+    # Generate payload
+    payload = make_payload(rng, payload_size, payload_profile)
     
     return NodeEntity(
         entity_key=entity_key,
@@ -357,6 +490,7 @@ def create_workload(
     seed: int,
     status: str | None = None,
     assigned_node: str | None = None,
+    payload_profile: str = "random",
 ) -> WorkloadEntity:
     """Create a single Workload entity with randomized attributes.
     
@@ -388,8 +522,8 @@ def create_workload(
         else:
             assigned_node = ""
     
-    # Generate random payload
-    payload = bytes(rng.getrandbits(8) for _ in range(payload_size))
+    # Generate payload
+    payload = make_payload(rng, payload_size, payload_profile)
     
     return WorkloadEntity(
         entity_key=entity_key,
@@ -414,10 +548,18 @@ def create_workload(
 
 @dataclass
 class BlockData:
-    """Data for a single block containing nodes and their workloads."""
+    """Data for a single block containing nodes and their workloads.
+    
+    updates and deletes target workloads created earlier in the same block and are
+    applied after all creates (last operation in the block wins). version_updates
+    rewrite workloads created in earlier blocks as a new version.
+    """
     block_num: int
     nodes: list[NodeEntity]
     workloads: list[WorkloadEntity]
+    updates: list[WorkloadEntity] = field(default_factory=list)
+    deletes: list[WorkloadEntity] = field(default_factory=list)
+    version_updates: list[WorkloadEntity] = field(default_factory=list)
 
 
 def generate_blocks(
@@ -429,6 +571,16 @@ def generate_blocks(
     start_block: int,
     seed: int,
     dc_num: int = 1,
+    numeric_bit_widths: list[int] | None = None,
+    numeric_attrs_per_width: int = 1,
+    same_block_updates: float = 0.0,
+    same_block_deletes: float = 0.0,
+    payload_profile: str = "random",
+    update_ratio: float = 0.0,
+    ttl_blocks: int | None = None,
+    ops_per_tx: tuple[int, int] | None = None,
+    node_offset: int = 0,
+    workload_offset: int = 0,
 ) -> Iterator[BlockData]:
     """
     Generate blocks with nodes and their associated workloads.
@@ -437,6 +589,20 @@ def generate_blocks(
     - N nodes (nodes_per_block)
     - For each node: M workloads (workloads_per_node)
     
+    Each node and its workloads form one transaction: the node is operation 0 and
+    its workloads follow. $sequence counts operations across the whole block.
+    With ops_per_tx = (min, max), the creates are instead regrouped in $sequence
+    order into transactions of a uniformly sampled size between min and max.
+    
+    Same-block updates/deletes are issued in one extra transaction after all
+    creates (tx_index = number of create transactions), so they always follow the
+    create.
+    
+    Updates of workloads from earlier blocks (update_ratio) follow in the next
+    transaction (after the churn transaction if there is one, so transaction
+    indices have no gaps). Each picks a distinct live workload
+    and writes a new version with a new status and payload; the expiration is kept.
+    
     Args:
         num_blocks: Number of blocks to generate
         nodes_per_block: Number of nodes per block
@@ -446,19 +612,35 @@ def generate_blocks(
         start_block: Starting block number
         seed: Random seed
         dc_num: Data center number (default: 1)
+        numeric_bit_widths: Bit widths of extra numeric attributes (default: none)
+        numeric_attrs_per_width: Extra numeric attributes per bit width
+        same_block_updates: Fraction of workloads updated in their creation block
+        same_block_deletes: Fraction of workloads deleted in their creation block
+        payload_profile: Payload content profile (see make_payload)
+        update_ratio: Updates of earlier workloads per block, as a fraction of the
+            workloads created per block
+        ttl_blocks: Fixed TTL in blocks for all entities (default: sampled)
+        ops_per_tx: (min, max) operations per create transaction (default: one
+            transaction per node and its workloads)
+        node_offset: Node numbers start after this (ids and keys derive from them)
+        workload_offset: Workload numbers start after this
     """
     rng = random.Random(f"{seed}:blocks")
     
+    # Live workloads from earlier blocks (payload dropped to bound memory)
+    update_pool: list[WorkloadEntity] = []
+    
     # Global counters for unique IDs
-    node_counter = 0
-    workload_counter = 0
+    node_counter = node_offset
+    workload_counter = workload_offset
     
     for block_idx in range(num_blocks):
         current_block = start_block + block_idx
         nodes = []
         workloads = []
+        sequence = 0
         
-        for _ in range(nodes_per_block):
+        for tx_index in range(nodes_per_block):
             node_counter += 1
             
             # Determine if this node is busy (has assigned workload)
@@ -473,7 +655,20 @@ def generate_blocks(
                 block=current_block,
                 seed=seed,
                 status=node_status,
+                payload_profile=payload_profile,
             )
+            if ttl_blocks:
+                node.ttl = ttl_blocks
+            node.tx_index = tx_index
+            node.op_index = 0
+            node.sequence = sequence
+            sequence += 1
+            if numeric_bit_widths:
+                node.extra_numeric = make_extra_numeric_attrs(
+                    random.Random(f"{seed}:extra:{node.node_id}"),
+                    numeric_bit_widths,
+                    numeric_attrs_per_width,
+                )
             nodes.append(node)
             
             # Create workloads for this node
@@ -497,13 +692,100 @@ def generate_blocks(
                     seed=seed,
                     status=wl_status,
                     assigned_node=wl_assigned,
+                    payload_profile=payload_profile,
                 )
+                if ttl_blocks:
+                    workload.ttl = ttl_blocks
+                workload.tx_index = tx_index
+                workload.op_index = wl_idx + 1
+                workload.sequence = sequence
+                sequence += 1
+                if numeric_bit_widths:
+                    workload.extra_numeric = make_extra_numeric_attrs(
+                        random.Random(f"{seed}:extra:{workload.workload_id}"),
+                        numeric_bit_widths,
+                        numeric_attrs_per_width,
+                    )
                 workloads.append(workload)
         
+        # Regroup creates into transactions of sampled size (own RNG, entities unchanged)
+        num_txs = nodes_per_block
+        if ops_per_tx:
+            tx_rng = random.Random(f"{seed}:tx:{current_block}")
+            creates = sorted([*nodes, *workloads], key=lambda entity: entity.sequence)
+            num_txs = 0
+            start = 0
+            while start < len(creates):
+                tx_size = tx_rng.randint(*ops_per_tx)
+                for op_index, entity in enumerate(creates[start:start + tx_size]):
+                    entity.tx_index = num_txs
+                    entity.op_index = op_index
+                num_txs += 1
+                start += tx_size
+        
+        # Same-block churn uses its own RNG so the created entities stay identical
+        updates = []
+        deletes = []
+        if same_block_updates > 0 or same_block_deletes > 0:
+            churn_rng = random.Random(f"{seed}:same-block:{current_block}")
+            op_index = 0
+            for workload in workloads:
+                r = churn_rng.random()
+                if r < same_block_deletes:
+                    target = deletes
+                    op = replace(workload)
+                elif r < same_block_deletes + same_block_updates:
+                    target = updates
+                    op = replace(workload, status="completed")
+                else:
+                    continue
+                op.tx_index = num_txs
+                op.op_index = op_index
+                op.sequence = sequence
+                op_index += 1
+                sequence += 1
+                target.append(op)
+        
+        # Updates of earlier workloads also use their own RNG
+        version_updates = []
+        if update_ratio > 0:
+            update_pool = [wl for wl in update_pool if wl.block + wl.ttl > current_block]
+            update_rng = random.Random(f"{seed}:updates:{current_block}")
+            num_updates = min(round(update_ratio * len(workloads)), len(update_pool))
+            update_tx = num_txs + 1 if updates or deletes else num_txs
+            picked = sorted(update_rng.sample(range(len(update_pool)), num_updates))
+            for op_index, pool_index in enumerate(picked):
+                previous = update_pool[pool_index]
+                op = replace(
+                    previous,
+                    status=sample_from_distribution(update_rng, get_workload_status_distribution()),
+                    payload=make_payload(update_rng, payload_size, payload_profile),
+                    block=current_block,
+                    created_block=previous.created_block or previous.block,
+                    ttl=previous.block + previous.ttl - current_block,
+                    tx_index=update_tx,
+                    op_index=op_index,
+                    sequence=sequence,
+                )
+                sequence += 1
+                version_updates.append(op)
+                update_pool[pool_index] = replace(op, payload=b"")
+            
+            # Workloads of this block become update targets from the next block on
+            deleted = {wl.entity_key for wl in deletes}
+            final = {wl.entity_key: wl for wl in updates}
+            update_pool += [
+                replace(final.get(wl.entity_key, wl), payload=b"")
+                for wl in workloads if wl.entity_key not in deleted
+            ]
+        
         yield BlockData(
             block_num=current_block,
             nodes=nodes,
             workloads=workloads,
+            updates=updates,
+            deletes=deletes,
+            version_updates=version_updates,
         )
 
 
@@ -517,7 +799,7 @@ def node_to_sql_inserts(node: NodeEntity, creator_address: str) -> list[tuple[st
     
     Returns list of (sql, params) tuples for:
     - string_attributes (4 custom + 3 system = 7 rows)
-    - numeric_attributes (4 custom + 5 system = 9 rows)
+    - numeric_attributes (4 custom + 5 system = 9 rows, plus extra_numeric)
     - payloads (1 row)
     """
     inserts = []
@@ -541,9 +823,7 @@ def node_to_sql_inserts(node: NodeEntity, creator_address: str) -> list[tuple[st
     
     for key, value in string_attrs:
         inserts.append((
-            """INSERT INTO string_attributes 
-               (entity_key, from_block, to_block, key, value) 
-               VALUES (?, ?, ?, ?, ?)""",
+            STRING_ATTR_INSERT_SQL,
             (entity_key, block, expires_at_block, key, value)
         ))
     
@@ -553,6 +833,7 @@ def node_to_sql_inserts(node: NodeEntity, creator_address: str) -> list[tuple[st
         ("ram_gb", node.ram_gb),
         ("price_hour", node.price_hour),
         ("avail_hours", node.avail_hours),
+        *node.extra_numeric.items(),
         # System attributes
         ("$createdAtBlock", block),
         ("$expiration", expires_at_block),
@@ -563,9 +844,7 @@ def node_to_sql_inserts(node: NodeEntity, creator_address: str) -> list[tuple[st
 
     for key, value in numeric_attrs:
         inserts.append((
-            """INSERT INTO numeric_attributes 
-               (entity_key, from_block, to_block, key, value) 
-               VALUES (?, ?, ?, ?, ?)""",
+            NUMERIC_ATTR_INSERT_SQL,
             (entity_key, block, expires_at_block, key, value)
         ))
     
@@ -574,10 +853,8 @@ def node_to_sql_inserts(node: NodeEntity, creator_address: str) -> list[tuple[st
     numeric_attrs_json = "{" + ", ".join(f'"{k}": {v}' for k, v in numeric_attrs) + "}"
     
     inserts.append((
-        """INSERT INTO payloads 
-           (entity_key, from_block, to_block, payload, content_type, string_attributes, numeric_attributes) 
-           VALUES (?, ?, ?, ?, ?, ?, ?)""",
-        (entity_key, block, expires_at_block, node.payload, "application/octet-stream", 
+        PAYLOAD_INSERT_SQL,
+        (entity_key, block, expires_at_block, node.payload, node.content_type,
          string_attrs_json, numeric_attrs_json)
     ))
     
@@ -590,7 +867,7 @@ def workload_to_sql_inserts(workload: WorkloadEntity, creator_address: str) -> l
     
     Returns list of (sql, params) tuples for:
     - string_attributes (6 custom + 3 system = 9 rows)
-    - numeric_attributes (3 custom + 5 system = 8 rows)
+    - numeric_attributes (3 custom + 5 system = 8 rows, plus extra_numeric)
     - payloads (1 row)
     """
     inserts = []
@@ -615,9 +892,7 @@ def workload_to_sql_inserts(workload: WorkloadEntity, creator_address: str) -> l
     
     for key, value in string_attrs:
         inserts.append((
-            """INSERT INTO string_attributes 
-               (entity_key, from_block, to_block, key, value) 
-               VALUES (?, ?, ?, ?, ?)""",
+            STRING_ATTR_INSERT_SQL,
             (entity_key, block, expires_at_block, key, value)
         ))
     
@@ -626,8 +901,9 @@ def workload_to_sql_inserts(workload: WorkloadEntity, creator_address: str) -> l
         ("req_cpu", workload.req_cpu),
         ("req_ram", workload.req_ram),
         ("max_hours", workload.max_hours),
-        # System attributes
-        ("$createdAtBlock", block),
+        *workload.extra_numeric.items(),
+        # System attributes ($createdAtBlock stays at the first version's block)
+        ("$createdAtBlock", workload.created_block or block),
         ("$expiration", expires_at_block),
         ("$opIndex", workload.op_index),
         ("$sequence", workload.sequence),
@@ -636,9 +912,7 @@ def workload_to_sql_inserts(workload: WorkloadEntity, creator_address: str) -> l
     
     for key, value in numeric_attrs:
         inserts.append((
-            """INSERT INTO numeric_attributes 
-               (entity_key, from_block, to_block, key, value) 
-               VALUES (?, ?, ?, ?, ?)""",
+            NUMERIC_ATTR_INSERT_SQL,
             (entity_key, block, expires_at_block, key, value)
         ))
     
@@ -647,10 +921,8 @@ def workload_to_sql_inserts(workload: WorkloadEntity, creator_address: str) -> l
     numeric_attrs_json = "{" + ", ".join(f'"{k}": {v}' for k, v in numeric_attrs) + "}"
     
     inserts.append((
-        """INSERT INTO payloads 
-           (entity_key, from_block, to_block, payload, content_type, string_attributes, numeric_attributes) 
-           VALUES (?, ?, ?, ?, ?, ?, ?)""",
-        (entity_key, block, expires_at_block, workload.payload, "application/octet-stream",
+        PAYLOAD_INSERT_SQL,
+        (entity_key, block, expires_at_block, workload.payload, workload.content_type,
          string_attrs_json, numeric_attrs_json)
     ))
     
@@ -720,6 +992,234 @@ CREATE INDEX IF NOT EXISTS idx_payloads_delete ON payloads (to_block);
 SCHEMA_SQL = SCHEMA_TABLES_SQL + INDEX_SQL
 
 
+def expected_block_order(block_data: BlockData) -> tuple[dict[bytes, tuple[int, int, int] | None], list[str]]:
+    """
+    Recompute the ordering attributes each entity must end up with after a block.
+    
+    Only the transaction of each operation is taken from the generator. The block's
+    operations are listed in submission order (each node followed by its workloads,
+    then same-block updates/deletes in workload order, then version updates), and
+    $opIndex and $sequence are recomputed as positions in the transaction and block.
+    
+    Returns:
+        (entity_key -> (tx, op, sequence), or None if the last operation deletes it;
+         problems with the operation order itself)
+    """
+    per_node = len(block_data.workloads) // len(block_data.nodes) if block_data.nodes else 0
+    operations: list[tuple[str, NodeEntity | WorkloadEntity]] = []
+    for i, node in enumerate(block_data.nodes):
+        operations.append(("create", node))
+        operations += [("create", wl) for wl in block_data.workloads[i * per_node:(i + 1) * per_node]]
+    operations += [("create", wl) for wl in block_data.workloads[len(block_data.nodes) * per_node:]]
+    position = {wl.entity_key: i for i, wl in enumerate(block_data.workloads)}
+    churn = [("update", wl) for wl in block_data.updates] + [("delete", wl) for wl in block_data.deletes]
+    operations += sorted(churn, key=lambda operation: position[operation[1].entity_key])
+    operations += [("update", wl) for wl in block_data.version_updates]
+    
+    expected: dict[bytes, tuple[int, int, int] | None] = {}
+    problems = []
+    ops_in_tx: dict[int, int] = {}
+    last_tx = -1
+    for sequence, (op, entity) in enumerate(operations):
+        if entity.tx_index < last_tx:
+            problems.append(
+                f"block {block_data.block_num}: operation {sequence} is in tx {entity.tx_index} "
+                f"after tx {last_tx} (transactions out of order)"
+            )
+        last_tx = max(last_tx, entity.tx_index)
+        op_index = ops_in_tx.get(entity.tx_index, 0)
+        ops_in_tx[entity.tx_index] = op_index + 1
+        expected[entity.entity_key] = None if op == "delete" else (entity.tx_index, op_index, sequence)
+    return expected, problems
+
+
+def audit_block(conn: sqlite3.Connection, block_data: BlockData) -> list[str]:
+    """
+    Read back the ordering attributes committed for a block and compare them with
+    the order recomputed by expected_block_order.
+    
+    conn should be a separate connection, so only committed rows are seen.
+    
+    Returns:
+        List of mismatch descriptions (empty if the block is consistent)
+    """
+    expected, mismatches = expected_block_order(block_data)
+    
+    cursor = conn.cursor()
+    cursor.execute("""
+        SELECT entity_key, key, value FROM numeric_attributes
+        WHERE from_block = ? AND to_block > ? AND key IN ('$txIndex', '$opIndex', '$sequence')
+    """, (block_data.block_num, block_data.block_num))
+    
+    stored: dict[bytes, dict[str, int]] = {}
+    for entity_key, key, value in cursor.fetchall():
+        stored.setdefault(entity_key, {})[key] = value
+    
+    for entity_key, order in expected.items():
+        if order is None:
+            continue
+        attrs = stored.get(entity_key, {})
+        actual = (attrs.get("$txIndex"), attrs.get("$opIndex"), attrs.get("$sequence"))
+        if actual != order:
+            mismatches.append(
+                f"block {block_data.block_num} entity {entity_key.hex()[:16]}: "
+                f"expected tx/op/seq {order}, stored {actual}"
+            )
+    live = {entity_key for entity_key, order in expected.items() if order is not None}
+    for entity_key in stored.keys() - live:
+        mismatches.append(
+            f"block {block_data.block_num} entity {entity_key.hex()[:16]}: not generated in this block"
+        )
+    
+    # Updated workloads must show their final status
+    for workload in [*block_data.updates, *block_data.version_updates]:
+        cursor.execute("""
+            SELECT value FROM string_attributes
+            WHERE entity_key = ? AND key = 'status' AND from_block <= ? AND to_block > ?
+        """, (workload.entity_key, block_data.block_num, block_data.block_num))
+        rows = cursor.fetchall()
+        if rows != [(workload.status,)]:
+            mismatches.append(
+                f"block {block_data.block_num} entity {workload.entity_key.hex()[:16]}: "
+                f"expected status {workload.status!r} after update, stored {rows}"
+            )
+    
+    # Deleted workloads must not be visible at the end of the block
+    for workload in block_data.deletes:
+        cursor.execute("""
+            SELECT COUNT(*) FROM payloads
+            WHERE entity_key = ? AND from_block <= ? AND to_block > ?
+        """, (workload.entity_key, block_data.block_num, block_data.block_num))
+        visible = cursor.fetchone()[0]
+        if visible:
+            mismatches.append(
+                f"block {block_data.block_num} entity {workload.entity_key.hex()[:16]}: "
+                f"still visible after same-block delete"
+            )
+    return mismatches
+
+
+def query_expired_entities(cursor: sqlite3.Cursor, block: int) -> list[bytes]:
+    """
+    Keys of entities whose expiration is the given block (GetExpiredEntities).
+    
+    Only versions still live until their expiration count, so entities deleted
+    before it (their version closed at the delete block) are left out.
+    """
+    cursor.execute("""
+        SELECT DISTINCT entity_key FROM numeric_attributes
+        WHERE key = '$expiration' AND value = ? AND to_block = ?
+    """, (block, block))
+    return [row[0] for row in cursor.fetchall()]
+
+
+def query_expiring_versions(cursor: sqlite3.Cursor, block: int) -> list[tuple[bytes, dict[str, str]]]:
+    """
+    Live versions that expire at the given block, with their string attributes.
+    
+    The versions counted by query_expired_entities (to_block is their expiration),
+    with the attributes the lifecycle log needs.
+    """
+    cursor.execute("""
+        SELECT entity_key, from_block FROM numeric_attributes
+        WHERE key = '$expiration' AND value = ? AND to_block = ?
+        ORDER BY entity_key
+    """, (block, block))
+    versions = []
+    for entity_key, from_block in cursor.fetchall():
+        cursor.execute(
+            "SELECT key, value FROM string_attributes WHERE entity_key = ? AND from_block = ?",
+            (entity_key, from_block),
+        )
+        versions.append((entity_key, dict(cursor.fetchall())))
+    return versions
+
+
+def apply_same_block_update(
+    cursor: sqlite3.Cursor, workload: WorkloadEntity, creator_address: str
+) -> list[tuple[str, tuple]]:
+    """Replace the version of a workload created in the same block; returns the inserts."""
+    for table in ("string_attributes", "numeric_attributes", "payloads"):
+        cursor.execute(
+            f"DELETE FROM {table} WHERE entity_key = ? AND from_block = ?",
+            (workload.entity_key, workload.block),
+        )
+    inserts = workload_to_sql_inserts(workload, creator_address)
+    for sql, params in inserts:
+        cursor.execute(sql, params)
+    return inserts
+
+
+def apply_delete(cursor: sqlite3.Cursor, entity_key: bytes, block: int) -> None:
+    """Close the live version of an entity at the given block."""
+    for table in ("string_attributes", "numeric_attributes", "payloads"):
+        cursor.execute(
+            f"UPDATE {table} SET to_block = ? WHERE entity_key = ? AND from_block <= ? AND to_block > ?",
+            (block, entity_key, block, block),
+        )
+
+
+def apply_version_update(
+    cursor: sqlite3.Cursor, workload: WorkloadEntity, creator_address: str
+) -> list[tuple[str, tuple]]:
+    """Close the live version of a workload and insert the new one at workload.block; returns the inserts."""
+    apply_delete(cursor, workload.entity_key, workload.block)
+    inserts = workload_to_sql_inserts(workload, creator_address)
+    for sql, params in inserts:
+        cursor.execute(sql, params)
+    return inserts
+
+
+def write_lifecycle_events(
+    log: TextIO,
+    block_data: BlockData,
+    fingerprint: str = "",
+    expiring: list[tuple[bytes, dict[str, str]]] | None = None,
+) -> None:
+    """
+    Append one JSON line per operation in the block, in apply order.
+    
+    Creates carry expires_at (the block the entity's version ends), so the
+    expected state of any key can be replayed to any block height. Expirations
+    (from query_expiring_versions) come first as expire events without a
+    sequence: those entities are no longer visible at this block.
+    """
+    for entity_key, attrs in expiring or []:
+        entity_type = attrs.get("type", "")
+        log.write(json.dumps({
+            "block": block_data.block_num,
+            "op": "expire",
+            "type": entity_type,
+            "id": attrs.get("node_id" if entity_type == NODE else "workload_id", ""),
+            "entity_key": entity_key.hex(),
+            "status": attrs.get("status", ""),
+            "fingerprint": fingerprint,
+        }) + "\n")
+    
+    creates = [(NODE, node.node_id, node) for node in block_data.nodes]
+    creates += [(WORKLOAD, wl.workload_id, wl) for wl in block_data.workloads]
+    events = [("create", entity_type, entity_id, entity) for entity_type, entity_id, entity in creates]
+    events += [("update", WORKLOAD, wl.workload_id, wl) for wl in block_data.updates]
+    events += [("update", WORKLOAD, wl.workload_id, wl) for wl in block_data.version_updates]
+    events += [("delete", WORKLOAD, wl.workload_id, wl) for wl in block_data.deletes]
+    events.sort(key=lambda event: event[3].sequence)
+    
+    for op, entity_type, entity_id, entity in events:
+        event = {
+            "block": block_data.block_num,
+            "sequence": entity.sequence,
+            "op": op,
+            "type": entity_type,
+            "id": entity_id,
+            "entity_key": entity.entity_key.hex(),
+        }
+        if op != "delete":
+            event["status"] = entity.status
+            event["expires_at"] = entity.block + entity.ttl
+        event["fingerprint"] = fingerprint
+        log.write(json.dumps(event) + "\n")
+
+
 def drop_indexes(conn: sqlite3.Connection):
     """Drop all indexes to speed up bulk inserts."""
     print(f"Dropping indexes... - {datetime.now().strftime('%H:%M:%S')}")
@@ -746,13 +1246,18 @@ def create_indexes(conn: sqlite3.Connection):
     print(f"Indexes created in {elapsed:.1f}s - {datetime.now().strftime('%H:%M:%S')}")
 
 
-def init_database(db_path: str, input_db: str | None = None) -> sqlite3.Connection:
+def init_database(
+    db_path: str,
+    input_db: str | None = None,
+    page_size: str | None = None,
+) -> sqlite3.Connection:
     """
     Initialize database, optionally copying from input database.
     
     Args:
         db_path: Path to output database
         input_db: Optional path to input database to copy from
+        page_size: Page size for a new database (ignored when copying input_db)
         
     Returns:
         SQLite connection to the new/copied database
@@ -769,6 +1274,8 @@ def init_database(db_path: str, input_db: str | None = None) -> sqlite3.Connecti
     else:
         # Create fresh database with schema (tables only, indexes later)
         conn = sqlite3.connect(db_path)
+        if page_size:
+            conn.execute(f"PRAGMA page_size = {page_size}")
         conn.executescript(SCHEMA_TABLES_SQL)
     
     # Set pragmas for performance
@@ -801,6 +1308,64 @@ def configure_memory(conn: sqlite3.Connection, memory_gb: int) -> None:
     print(f"Memory config: {cache_gb}GB cache, {mmap_gb}GB mmap")
 
 
+def parse_pragma(value: str) -> tuple[str, str]:
+    """Parse a --pragma NAME=VALUE argument (NAME from TUNABLE_PRAGMAS)."""
+    name, _, setting = value.partition("=")
+    name = name.strip().lower()
+    setting = setting.strip()
+    if name not in TUNABLE_PRAGMAS:
+        raise argparse.ArgumentTypeError(f"unsupported pragma {name!r} (choose from {', '.join(TUNABLE_PRAGMAS)})")
+    if not re.fullmatch(r"-?[A-Za-z0-9_]+", setting):
+        raise argparse.ArgumentTypeError(f"invalid value for pragma {name}: {setting!r}")
+    return name, setting
+
+
+def apply_pragmas(conn: sqlite3.Connection, pragmas: dict[str, str]) -> None:
+    """Apply PRAGMA overrides on top of the script's defaults."""
+    for name, setting in pragmas.items():
+        conn.execute(f"PRAGMA {name} = {setting}")
+
+
+def active_pragmas(conn: sqlite3.Connection) -> dict[str, Any]:
+    """Read back the active value of every tunable PRAGMA."""
+    return {name: conn.execute(f"PRAGMA {name}").fetchone()[0] for name in TUNABLE_PRAGMAS}
+
+
+def format_pragmas(pragmas: dict[str, Any]) -> str:
+    """Compact NAME=VALUE;... form of active_pragmas for the block CSV."""
+    return ";".join(f"{name}={value}" for name, value in pragmas.items())
+
+
+def workload_fingerprint(config: dict[str, Any]) -> str:
+    """Stable short hash of the run configuration, used to tell runs apart in merged logs."""
+    canonical = json.dumps(config, sort_keys=True, separators=(",", ":"), default=str)
+    return hashlib.sha256(canonical.encode()).hexdigest()[:12]
+
+
+def create_run_dir(run_dir: str, name: str) -> str:
+    """
+    Create a new <run_dir>/<name>_<timestamp>_<pid>/ directory and point <run_dir>/latest at it.
+    
+    The timestamp has microseconds and the directory is created as soon as it is
+    named (retrying with a new name if it exists), so concurrent runs never share one.
+    """
+    while True:
+        run_path = os.path.join(
+            run_dir, f"{name}_{datetime.now().strftime('%Y%m%d_%H%M%S_%f')}_{os.getpid()}"
+        )
+        try:
+            os.makedirs(run_path)
+            break
+        except FileExistsError:
+            continue
+    latest = os.path.join(run_dir, "latest")
+    if os.path.lexists(latest + ".tmp"):
+        os.remove(latest + ".tmp")
+    os.symlink(os.path.basename(run_path), latest + ".tmp")
+    os.replace(latest + ".tmp", latest)
+    return run_path
+
+
 def get_max_block(conn: sqlite3.Connection) -> int:
     """Get the maximum block number from existing data."""
     cursor = conn.execute(
@@ -810,6 +1375,12 @@ def get_max_block(conn: sqlite3.Connection) -> int:
     return result if result is not None else 0
 
 
+def database_size(path: str) -> int:
+    """Size in bytes of a database file plus its WAL (committed pages not yet checkpointed)."""
+    wal_path = path + "-wal"
+    return os.path.getsize(path) + (os.path.getsize(wal_path) if os.path.exists(wal_path) else 0)
+
+
 # =============================================================================
 # Top-Level Generation Functions
 # =============================================================================
@@ -825,7 +1396,24 @@ def append_blocks(
     seed: int,
     creator_address: str = "0x0000000000000000000000000000000000dc0001",
     batch_size: int = 1,
-) -> tuple[int, int, int]:
+    numeric_bit_widths: list[int] | None = None,
+    numeric_attrs_per_width: int = 1,
+    audit: bool = False,
+    same_block_updates: float = 0.0,
+    same_block_deletes: float = 0.0,
+    block_csv: TextIO | None = None,
+    testname: str = "",
+    db_path: str | None = None,
+    lifecycle_log: TextIO | None = None,
+    payload_profile: str = "random",
+    update_ratio: float = 0.0,
+    ttl_blocks: int | None = None,
+    ops_per_tx: tuple[int, int] | None = None,
+    compress: str = "none",
+    cold_start: dict[str, float] | None = None,
+    fingerprint: str = "",
+    pragmas: dict[str, Any] | None = None,
+) -> tuple[int, int, int, int]:
     """
     Generate and insert blocks with nodes and workloads together.
     
@@ -840,9 +1428,33 @@ def append_blocks(
         seed: Random seed
         creator_address: Creator address for entities
         batch_size: Commit every N blocks (default: 1 = commit per block)
+        numeric_bit_widths: Bit widths of extra numeric attributes (default: none)
+        numeric_attrs_per_width: Extra numeric attributes per bit width
+        audit: After each commit, read back the committed blocks' tx/op indices on a
+            separate connection and report mismatches (requires db_path)
+        same_block_updates: Fraction of workloads updated in their creation block
+        same_block_deletes: Fraction of workloads deleted in their creation block
+        block_csv: Open file receiving one CSV record per block (header written by caller)
+        testname: Value of the testname column in block_csv
+        db_path: Database path, used for the db_size_kb column (database plus WAL) in
+            block_csv and by audit
+        lifecycle_log: Open file receiving one JSON line per create/update/delete/expire
+        payload_profile: Payload content profile (see make_payload)
+        update_ratio: Updates of earlier workloads per block, as a fraction of the
+            workloads created per block
+        ttl_blocks: Fixed TTL in blocks for all entities; also times the expired
+            entities query after each block
+        ops_per_tx: (min, max) operations per create transaction (default: one
+            transaction per node and its workloads)
+        compress: Payload compression codec applied before insert (see COMPRESSION_CODECS)
+        cold_start: If given, receives first_block_ms, the apply time (write + commit)
+            of the first block after startup
+        fingerprint: Run fingerprint stamped on every block_csv record and lifecycle event
+        pragmas: Active SQLite settings (see active_pragmas), recorded in every block_csv
+            record and in a start event of the lifecycle_log
     
     Returns:
-        Tuple of (node_count, workload_count, final_block)
+        Tuple of (node_count, workload_count, final_block, audit_mismatches)
     """
     entities_per_block = nodes_per_block + (nodes_per_block * workloads_per_node)
     total_entities = num_blocks * entities_per_block
@@ -856,11 +1468,50 @@ def append_blocks(
     print()
     
     cursor = conn.cursor()
+    csv_writer = csv.writer(block_csv) if block_csv else None
+    pragma_settings = format_pragmas(pragmas or {})
+    if lifecycle_log:
+        lifecycle_log.write(json.dumps({
+            "block": start_block,
+            "op": "start",
+            "pragmas": pragmas or {},
+            "fingerprint": fingerprint,
+        }) + "\n")
+    # Audits read committed blocks on their own connection
+    if audit and not db_path:
+        raise ValueError("audit requires db_path")
+    audit_conn = sqlite3.connect(db_path) if audit else None
+    unaudited: list[BlockData] = []
+    
+    def audit_committed() -> int:
+        mismatches = 0
+        for committed in unaudited:
+            for mismatch in audit_block(audit_conn, committed):
+                mismatches += 1
+                if audit_mismatches + mismatches <= 10:
+                    print(f"  Audit mismatch: {mismatch}")
+        unaudited.clear()
+        return mismatches
+    
     node_count = 0
     workload_count = 0
     block_count = 0
+    update_count = 0
+    version_update_count = 0
+    delete_count = 0
+    audit_mismatches = 0
+    # Write time per operation type (excluding commit)
+    op_time_ms = {"create": 0.0, "update": 0.0, "delete": 0.0}
+    # Payload bytes before/after compression and compression time
+    raw_payload_total = 0
+    stored_payload_total = 0
+    compress_time_total_ms = 0.0
+    # Expired entities query per block (with ttl_blocks)
+    expired_count = 0
+    expired_query_ms: list[float] = []
     final_block = start_block
     start_time = time.time()
+    build_start = time.perf_counter()
     
     for block_data in generate_blocks(
         num_blocks=num_blocks,
@@ -870,12 +1521,40 @@ def append_blocks(
         payload_size=payload_size,
         start_block=start_block,
         seed=seed,
+        numeric_bit_widths=numeric_bit_widths,
+        numeric_attrs_per_width=numeric_attrs_per_width,
+        same_block_updates=same_block_updates,
+        same_block_deletes=same_block_deletes,
+        payload_profile=payload_profile,
+        update_ratio=update_ratio,
+        ttl_blocks=ttl_blocks,
+        ops_per_tx=ops_per_tx,
     ):
+        compress_start = time.perf_counter()
+        build_time_ms = (compress_start - build_start) * 1000
+        block_inserts = {"string_attributes": 0, "numeric_attributes": 0, "payloads": 0}
+        
+        # Compress payloads of everything written in this block (sizes count every payload
+        # inserted, including the versions replaced by same-block updates)
+        written = [*block_data.nodes, *block_data.workloads, *block_data.updates, *block_data.version_updates]
+        payload_bytes = sum(len(entity.payload) for entity in written)
+        if compress != "none":
+            for entity in written:
+                entity.payload = compress_payload(entity.payload, compress)
+                entity.content_type = payload_content_type(compress)
+        stored_payload_bytes = sum(len(entity.payload) for entity in written)
+        write_start = time.perf_counter()
+        compress_time_ms = (write_start - compress_start) * 1000
+        raw_payload_total += payload_bytes
+        stored_payload_total += stored_payload_bytes
+        compress_time_total_ms += compress_time_ms
+        
         # Insert all nodes in this block
         for node in block_data.nodes:
             inserts = node_to_sql_inserts(node, creator_address)
             for sql, params in inserts:
                 cursor.execute(sql, params)
+                block_inserts[INSERT_TABLES[sql]] += 1
             node_count += 1
         
         # Insert all workloads in this block
@@ -883,14 +1562,72 @@ def append_blocks(
             inserts = workload_to_sql_inserts(workload, creator_address)
             for sql, params in inserts:
                 cursor.execute(sql, params)
+                block_inserts[INSERT_TABLES[sql]] += 1
             workload_count += 1
         
+        # Same-block updates and deletes run after all creates, as typed sub-batches
+        update_start = time.perf_counter()
+        for workload in block_data.updates:
+            for sql, _ in apply_same_block_update(cursor, workload, creator_address):
+                block_inserts[INSERT_TABLES[sql]] += 1
+            update_count += 1
+        for workload in block_data.version_updates:
+            for sql, _ in apply_version_update(cursor, workload, creator_address):
+                block_inserts[INSERT_TABLES[sql]] += 1
+            version_update_count += 1
+        delete_start = time.perf_counter()
+        for workload in block_data.deletes:
+            apply_delete(cursor, workload.entity_key, block_data.block_num)
+            delete_count += 1
+        write_end = time.perf_counter()
+        
+        write_time_ms = (write_end - write_start) * 1000
+        create_time_ms = (update_start - write_start) * 1000
+        update_time_ms = (delete_start - update_start) * 1000
+        delete_time_ms = (write_end - delete_start) * 1000
+        op_time_ms["create"] += create_time_ms
+        op_time_ms["update"] += update_time_ms
+        op_time_ms["delete"] += delete_time_ms
+        
+        if audit:
+            unaudited.append(block_data)
+        
         block_count += 1
         final_block = block_data.block_num
         
-        # Commit every batch_size blocks
-        if block_count % batch_size == 0:
+        # Commit every batch_size blocks, and the final partial batch with the last block
+        commit_time_ms = 0.0
+        if block_count % batch_size == 0 or block_count == num_blocks:
+            commit_start = time.perf_counter()
             conn.commit()
+            commit_time_ms = (time.perf_counter() - commit_start) * 1000
+            if audit:
+                audit_mismatches += audit_committed()
+        
+        if cold_start is not None and block_count == 1:
+            cold_start["first_block_ms"] = write_time_ms + commit_time_ms
+        
+        if ttl_blocks:
+            query_start = time.perf_counter()
+            expired_count += len(query_expired_entities(cursor, block_data.block_num))
+            expired_query_ms.append((time.perf_counter() - query_start) * 1000)
+        
+        if lifecycle_log:
+            expiring = query_expiring_versions(cursor, block_data.block_num)
+            write_lifecycle_events(lifecycle_log, block_data, fingerprint, expiring)
+        
+        if csv_writer:
+            db_size_kb = database_size(db_path) // 1024 if db_path else 0
+            csv_writer.writerow([
+                testname, block_data.block_num,
+                len(block_data.nodes) + len(block_data.workloads),
+                len(block_data.updates) + len(block_data.version_updates), len(block_data.deletes),
+                block_inserts["string_attributes"], block_inserts["numeric_attributes"],
+                payload_bytes // 1024, f"{build_time_ms:.3f}", f"{write_time_ms:.3f}",
+                f"{create_time_ms:.3f}", f"{update_time_ms:.3f}", f"{delete_time_ms:.3f}",
+                f"{commit_time_ms:.3f}", db_size_kb, stored_payload_bytes // 1024, f"{compress_time_ms:.3f}",
+                fingerprint, pragma_settings,
+            ])
         
         # Progress every 100 blocks or 1000 entities
         if block_count % 100 == 0 or (node_count + workload_count) % 1000 == 0:
@@ -899,14 +1636,47 @@ def append_blocks(
             print(f"  Block {block_count:,}/{num_blocks:,} ({100*block_count/num_blocks:.1f}%) - "
                   f"{node_count + workload_count:,} entities - {rate:.0f}/sec - "
                   f"{datetime.now().strftime('%H:%M:%S')}")
+        
+        build_start = time.perf_counter()
     
     conn.commit()
+    if audit:
+        audit_mismatches += audit_committed()
+        audit_conn.close()
     elapsed = time.time() - start_time
     rate = (node_count + workload_count) / elapsed if elapsed > 0 else 0
     print(f"  Completed {block_count:,} blocks in {elapsed:.1f}s ({rate:.0f} entities/sec) - "
           f"{datetime.now().strftime('%H:%M:%S')}")
+    if update_count or delete_count or version_update_count:
+        if update_count or delete_count:
+            print(f"  Same-block ops: {update_count:,} updates, {delete_count:,} deletes")
+        if version_update_count:
+            print(f"  Version updates: {version_update_count:,} workloads rewritten")
+        total_ms = sum(op_time_ms.values())
+        op_counts = {
+            "create": node_count + workload_count,
+            "update": update_count + version_update_count,
+            "delete": delete_count,
+        }
+        print("  Write time by operation type (excluding commit):")
+        for op, time_ms in op_time_ms.items():
+            share = time_ms / total_ms if total_ms > 0 else 0
+            per_op = time_ms / op_counts[op] if op_counts[op] else 0
+            print(f"    {op:<8} {time_ms:>10.1f}ms ({share:>5.1%}) - {per_op:.3f}ms/op")
+    if compress != "none":
+        ratio = raw_payload_total / stored_payload_total if stored_payload_total else 0
+        print(f"  Compression ({compress}): {raw_payload_total / 1024**2:.1f} MB -> "
+              f"{stored_payload_total / 1024**2:.1f} MB ({ratio:.2f}x) in {compress_time_total_ms / 1000:.1f}s")
+    if expired_query_ms:
+        expired_query_ms.sort()
+        p95 = expired_query_ms[min(len(expired_query_ms) - 1, int(len(expired_query_ms) * 0.95))]
+        print(f"  Expired entities: {expired_count:,} ({expired_count / block_count:.1f}/block)")
+        print(f"  Expired query:    avg {sum(expired_query_ms) / len(expired_query_ms):.3f}ms, "
+              f"p95 {p95:.3f}ms, max {expired_query_ms[-1]:.3f}ms")
+    if audit:
+        print(f"  Audit: {block_count:,} blocks checked, {audit_mismatches:,} mismatches")
     
-    return node_count, workload_count, final_block
+    return node_count, workload_count, final_block, audit_mismatches
 
 
 # =============================================================================
@@ -959,6 +1729,12 @@ def main():
         default=10000,
         help="Payload size in bytes per entity (default: 10000)"
     )
+    parser.add_argument(
+        "--payload-profile",
+        choices=PAYLOAD_PROFILES,
+        default="random",
+        help="Payload content: random (incompressible), zero, json (text) or repeat (default: random)"
+    )
     parser.add_argument(
         "--seed", "-s",
         type=int,
@@ -978,16 +1754,139 @@ def main():
         help="Memory to use for SQLite cache+mmap in GB (default: 2)"
     )
     
+    parser.add_argument(
+        "--numeric-bits",
+        type=int,
+        nargs="+",
+        choices=NUMERIC_BIT_WIDTHS,
+        default=None,
+        help="Add extra numeric attributes with values spanning these bit widths (e.g. 8 32 64)"
+    )
+    parser.add_argument(
+        "--numeric-attrs-per-width",
+        type=int,
+        default=1,
+        help="Number of extra numeric attributes per --numeric-bits width (default: 1)"
+    )
+    parser.add_argument(
+        "--block-csv",
+        type=str,
+        default=None,
+        help="Append one CSV record per block (counts, build/write/commit times, db size) to this file"
+    )
+    parser.add_argument(
+        "--testname",
+        type=str,
+        default=None,
+        help="Value of the testname column in --block-csv (default: output file name without extension)"
+    )
+    parser.add_argument(
+        "--lifecycle-log",
+        type=str,
+        default=None,
+        help="Append one JSON line per create/update/delete (block, sequence, op, key) to this file"
+    )
+    parser.add_argument(
+        "--audit",
+        action="store_true",
+        help="After each block, read back $txIndex/$opIndex/$sequence and verify them"
+    )
+    parser.add_argument(
+        "--same-block-updates",
+        type=float,
+        default=0.0,
+        help="Fraction of workloads updated (status -> completed) in the block that creates them (default: 0)"
+    )
+    parser.add_argument(
+        "--same-block-deletes",
+        type=float,
+        default=0.0,
+        help="Fraction of workloads deleted in the block that creates them (default: 0)"
+    )
+    parser.add_argument(
+        "--pragma",
+        type=parse_pragma,
+        action="append",
+        default=[],
+        metavar="NAME=VALUE",
+        help=f"Override a SQLite setting, repeatable ({', '.join(TUNABLE_PRAGMAS)}); "
+             "page_size only applies to a new database"
+    )
+    parser.add_argument(
+        "--compress",
+        choices=COMPRESSION_CODECS,
+        default="none",
+        help="Compress each payload before storing it (default: none)"
+    )
+    parser.add_argument(
+        "--ops-per-tx",
+        type=str,
+        default=None,
+        help="Operations per create transaction: N, or MIN-MAX sampled uniformly per transaction "
+             "(default: one transaction per node and its workloads)"
+    )
+    parser.add_argument(
+        "--ttl-blocks",
+        type=int,
+        default=None,
+        help="Fixed TTL in blocks for all entities, e.g. 5 to stress expiration "
+             "(default: sampled from the TTL distribution)"
+    )
+    parser.add_argument(
+        "--update-ratio",
+        type=float,
+        default=0.0,
+        help="Rewrite live workloads from earlier blocks as new versions, per block as a fraction "
+             "of the workloads created (default: 0)"
+    )
+    parser.add_argument(
+        "--run-dir",
+        type=str,
+        default=None,
+        help="Write this run's outputs to a new <run-dir>/<output name>_<timestamp>_<pid>/ directory "
+             "(relative --block-csv/--lifecycle-log paths go inside; --block-csv defaults to blocks.csv)"
+    )
+    
     args = parser.parse_args()
     
     # Validate percentage-assigned range
     if not 0.0 <= args.percentage_assigned <= 1.0:
         parser.error("--percentage-assigned must be between 0.0 and 1.0")
     
+    # Validate same-block churn fractions
+    if args.same_block_updates < 0 or args.same_block_deletes < 0 or \
+            args.same_block_updates + args.same_block_deletes > 1.0:
+        parser.error("--same-block-updates and --same-block-deletes must be >= 0 and sum to at most 1.0")
+    
+    if args.update_ratio < 0:
+        parser.error("--update-ratio must be >= 0")
+    if args.ttl_blocks is not None and args.ttl_blocks < 1:
+        parser.error("--ttl-blocks must be >= 1")
+    
+    # Parse ops per transaction ("N" or "MIN-MAX")
+    ops_per_tx = None
+    if args.ops_per_tx:
+        try:
+            bounds = [int(v) for v in args.ops_per_tx.split("-")]
+        except ValueError:
+            parser.error("--ops-per-tx must be N or MIN-MAX")
+        if len(bounds) not in (1, 2) or bounds[0] < 1 or bounds[-1] < bounds[0]:
+            parser.error("--ops-per-tx must be N or MIN-MAX with 1 <= MIN <= MAX")
+        ops_per_tx = (bounds[0], bounds[-1])
+    
     # Generate random seed if not provided
     if args.seed is None:
         args.seed = random.randint(1, 2**31 - 1)
     
+    # Per-run directory with the run's arguments, so successive runs never append to the same files
+    if args.run_dir:
+        run_path = create_run_dir(args.run_dir, os.path.splitext(os.path.basename(args.output))[0])
+        args.block_csv = os.path.join(run_path, args.block_csv or "blocks.csv")
+        if args.lifecycle_log:
+            args.lifecycle_log = os.path.join(run_path, args.lifecycle_log)
+        with open(os.path.join(run_path, "run.json"), "w") as f:
+            json.dump(vars(args), f, indent=2)
+    
     # Calculate derived values
     entities_per_block = args.nodes_per_block + (args.nodes_per_block * args.workloads_per_node)
     total_nodes = args.blocks * args.nodes_per_block
@@ -1007,8 +1906,23 @@ def main():
     print(f"Workloads per node: {args.workloads_per_node}")
     print(f"Entities per block: {entities_per_block}")
     print(f"% assigned:         {args.percentage_assigned*100:.0f}%")
-    print(f"Payload size:       {args.payload_size:,} bytes")
+    print(f"Payload size:       {args.payload_size:,} bytes ({args.payload_profile})")
+    if args.compress != "none":
+        print(f"Compression:        {args.compress}")
     print(f"Seed:               {args.seed}")
+    if ops_per_tx:
+        print(f"Ops per tx:         {ops_per_tx[0]}" + (f"-{ops_per_tx[1]}" if ops_per_tx[1] != ops_per_tx[0] else ""))
+    if args.ttl_blocks:
+        print(f"TTL:                {args.ttl_blocks} blocks (fixed)")
+    if args.update_ratio > 0:
+        print(f"Update ratio:       {args.update_ratio:.2f} "
+              f"(~{round(args.update_ratio * args.nodes_per_block * args.workloads_per_node)} version updates/block)")
+    if args.numeric_bits:
+        print("Extra numeric attrs:")
+        for bits in args.numeric_bits:
+            min_val, max_val = numeric_attr_range(bits)
+            names = ", ".join(numeric_attr_name(bits, i) for i in range(1, args.numeric_attrs_per_width + 1))
+            print(f"  {bits:>2}-bit [{min_val}, {max_val:,}]: {names}")
     print()
     
     print(f"Expected totals:")
@@ -1018,21 +1932,67 @@ def main():
     print(f"  Est. added size:  ~{est_size_gb:.1f} GB")
     print()
     
+    # Startup phases are timed individually (ms)
+    cold_start: dict[str, float] = {}
+    phase_start = time.perf_counter()
+    
     # Initialize database
-    conn = init_database(args.output, args.input)
+    pragmas = dict(args.pragma)
+    conn = init_database(args.output, args.input, page_size=pragmas.get("page_size"))
     
-    # Configure memory settings
+    # Configure memory settings, then apply --pragma overrides
     configure_memory(conn, args.memory)
+    apply_pragmas(conn, pragmas)
+    settings = active_pragmas(conn)
+    cold_start["open_ms"] = (time.perf_counter() - phase_start) * 1000
+    print("PRAGMAs:            " + ", ".join(f"{name}={value}" for name, value in settings.items()))
     
     # Get starting block (after existing data if any)
+    phase_start = time.perf_counter()
     start_block = get_max_block(conn) + 1
+    cold_start["start_block_ms"] = (time.perf_counter() - phase_start) * 1000
     print(f"Starting block:     {start_block}")
+    
+    fingerprint = workload_fingerprint({
+        "input": os.path.basename(args.input) if args.input else None,
+        "start_block": start_block,
+        "blocks": args.blocks,
+        "nodes_per_block": args.nodes_per_block,
+        "workloads_per_node": args.workloads_per_node,
+        "percentage_assigned": args.percentage_assigned,
+        "payload_size": args.payload_size,
+        "payload_profile": args.payload_profile,
+        "seed": args.seed,
+        "batch_size": args.batch_size,
+        "numeric_bits": args.numeric_bits,
+        "numeric_attrs_per_width": args.numeric_attrs_per_width,
+        "same_block_updates": args.same_block_updates,
+        "same_block_deletes": args.same_block_deletes,
+        "update_ratio": args.update_ratio,
+        "ttl_blocks": args.ttl_blocks,
+        "ops_per_tx": ops_per_tx,
+        "compress": args.compress,
+        "pragmas": pragmas,
+    })
+    print(f"Fingerprint:        {fingerprint}")
     print()
     
+    # Open per-block CSV (appending, so several runs can share one file)
+    block_csv = None
+    testname = args.testname or os.path.splitext(os.path.basename(args.output))[0]
+    if args.block_csv:
+        write_header = not os.path.exists(args.block_csv) or os.path.getsize(args.block_csv) == 0
+        block_csv = open(args.block_csv, "a", newline="")
+        if write_header:
+            csv.writer(block_csv).writerow(BLOCK_CSV_HEADER)
+    
+    # Open lifecycle event log (appending, like the per-block CSV)
+    lifecycle_log = open(args.lifecycle_log, "a") if args.lifecycle_log else None
+    
     # Generate data
     start_time = time.time()
     
-    node_count, workload_count, final_block = append_blocks(
+    node_count, workload_count, final_block, audit_mismatches = append_blocks(
         conn=conn,
         num_blocks=args.blocks,
         nodes_per_block=args.nodes_per_block,
@@ -1042,7 +2002,28 @@ def main():
         start_block=start_block,
         seed=args.seed,
         batch_size=args.batch_size,
+        numeric_bit_widths=args.numeric_bits,
+        numeric_attrs_per_width=args.numeric_attrs_per_width,
+        audit=args.audit,
+        same_block_updates=args.same_block_updates,
+        same_block_deletes=args.same_block_deletes,
+        block_csv=block_csv,
+        testname=testname,
+        db_path=args.output,
+        lifecycle_log=lifecycle_log,
+        payload_profile=args.payload_profile,
+        update_ratio=args.update_ratio,
+        ttl_blocks=args.ttl_blocks,
+        ops_per_tx=ops_per_tx,
+        compress=args.compress,
+        cold_start=cold_start,
+        fingerprint=fingerprint,
+        pragmas=settings,
     )
+    if block_csv:
+        block_csv.close()
+    if lifecycle_log:
+        lifecycle_log.close()
     
     # Update last_block
     conn.execute(
@@ -1071,7 +2052,26 @@ def main():
     print(f"Database size:     {db_size / (1024**3):.2f} GB")
     print(f"Output:            {args.output}")
     print(f"Seed:              {args.seed}")
+    print(f"Fingerprint:       {fingerprint}")
+    print("PRAGMAs:           " + ", ".join(f"{name}={value}" for name, value in settings.items()))
+    if args.block_csv:
+        print(f"Block CSV:         {args.block_csv} (testname: {testname})")
+    if args.lifecycle_log:
+        print(f"Lifecycle log:     {args.lifecycle_log}")
+    
+    print()
+    print("--- Cold Start (ms) ---")
+    print(f"Open + configure:  {cold_start['open_ms']:.2f}" + (" (includes copying --input)" if args.input else ""))
+    print(f"Starting block:    {cold_start['start_block_ms']:.2f}")
+    if "first_block_ms" in cold_start:
+        print(f"First block apply: {cold_start['first_block_ms']:.2f} (write + commit)")
+    
+    if args.audit:
+        print(f"Audit mismatches:  {audit_mismatches:,}")
+        if audit_mismatches:
+            return 1
+    return 0
 
 
 if __name__ == "__main__":
-    main()
+    sys.exit(main())
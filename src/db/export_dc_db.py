@@ -0,0 +1,106 @@
+"""
+Export the live entities of a Data Center database to portable JSON lines.
+
+Each line holds one entity as of the chosen block: key, owner, content type,
+string/numeric attributes and the payload (base64), so datasets can be
+inspected, diffed and re-imported with import_dc_db.py.
+
+Usage:
+    uv run python -m src.db.export_dc_db data/dc_blocks.db data/dc_blocks.jsonl
+    uv run python -m src.db.export_dc_db data/dc_blocks.db data/dc_blocks_b500.jsonl --block 500
+"""
+
+import argparse
+import base64
+import json
+import os
+import sqlite3
+import sys
+import time
+from typing import Any, Iterator
+
+from .query_dc_benchmark import get_current_block
+
+
+def iter_entities(conn: sqlite3.Connection, block: int) -> Iterator[dict[str, Any]]:
+    """Yield every entity live at block, ordered by entity key."""
+    entities = conn.cursor()
+    attrs = conn.cursor()
+    entities.execute("""
+        SELECT entity_key, from_block, to_block, payload, content_type FROM payloads
+        WHERE from_block <= ? AND to_block > ?
+        ORDER BY entity_key
+    """, (block, block))
+    for entity_key, from_block, to_block, payload, content_type in entities:
+        attrs.execute("""
+            SELECT key, value FROM string_attributes
+            WHERE entity_key = ? AND from_block <= ? AND to_block > ?
+        """, (entity_key, block, block))
+        string_attrs = dict(attrs.fetchall())
+        attrs.execute("""
+            SELECT key, value FROM numeric_attributes
+            WHERE entity_key = ? AND from_block <= ? AND to_block > ?
+        """, (entity_key, block, block))
+        numeric_attrs = dict(attrs.fetchall())
+        yield {
+            "entity_key": entity_key.hex(),
+            "from_block": from_block,
+            "to_block": to_block,
+            "expires_at": numeric_attrs.get("$expiration", to_block),
+            "owner": string_attrs.get("$owner", ""),
+            "content_type": content_type,
+            "string_attributes": string_attrs,
+            "numeric_attributes": numeric_attrs,
+            "payload": base64.b64encode(payload).decode("ascii"),
+        }
+
+
+def main():
+    parser = argparse.ArgumentParser(
+        description="Export the live entities of a Data Center database to JSON lines"
+    )
+    parser.add_argument(
+        "database",
+        type=str,
+        help="Path to the database to export"
+    )
+    parser.add_argument(
+        "output",
+        type=str,
+        help="Output JSONL file"
+    )
+    parser.add_argument(
+        "--block",
+        type=int,
+        default=None,
+        help="Export entities live at this block (default: current block)"
+    )
+
+    args = parser.parse_args()
+
+    if not os.path.exists(args.database):
+        print(f"Error: Database not found: {args.database}", file=sys.stderr)
+        return 1
+
+    conn = sqlite3.connect(args.database)
+    block = args.block if args.block is not None else get_current_block(conn)
+
+    print(f"Exporting entities live at block {block:,} from {args.database}...")
+    start_time = time.time()
+    count = 0
+    with open(args.output, "w") as f:
+        for entity in iter_entities(conn, block):
+            f.write(json.dumps(entity) + "\n")
+            count += 1
+            if count % 10000 == 0:
+                print(f"  {count:,} entities")
+    conn.close()
+
+    elapsed = time.time() - start_time
+    print(f"Exported {count:,} entities in {elapsed:.1f}s")
+    print(f"Output: {args.output} ({os.path.getsize(args.output) / (1024**2):.1f} MB)")
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -58,6 +58,9 @@ SYSTEM_NUMERIC_ATTRS = ["$createdAtBlock", "$expiration", "$opIndex", "$sequence
 DEFAULT_NODE_UPDATES_PER_BLOCK = 60
 DEFAULT_WORKLOAD_UPDATES_PER_BLOCK = 600
 
+# Payload content profiles (--payload-profile)
+PAYLOAD_PROFILES = ["random", "zero", "json", "repeat"]
+
 
 @dataclass
 class NodeEntity:
@@ -254,6 +257,32 @@ def sample_from_distribution(rng: random.Random, dist: list[tuple[any, float]])
 # ID Generation (deterministic)
 # =============================================================================
 
+def make_payload(rng: random.Random, payload_size: int, profile: str = "random") -> bytes:
+    """Generate a payload of payload_size bytes with the given content profile.
+    
+    - random:  incompressible random bytes
+    - zero:    all zero bytes
+    - json:    JSON-like text records
+    - repeat:  a random 64-byte pattern repeated
+    """
+    if profile == "zero":
+        return bytes(payload_size)
+    if profile == "json":
+        words = ["alpha", "beta", "gamma", "delta", "compute", "storage", "region", "status"]
+        records = []
+        size = 0
+        while size < payload_size:
+            record = (f'{{"id": {rng.randint(0, 999999)}, "name": "{rng.choice(words)}", '
+                      f'"value": {rng.random():.6f}, "tags": ["{rng.choice(words)}", "{rng.choice(words)}"]}}')
+            records.append(record)
+            size += len(record) + 2
+        return ("[" + ", ".join(records) + "]").encode()[:payload_size]
+    if profile == "repeat":
+        pattern = bytes(rng.getrandbits(8) for _ in range(64))
+        return (pattern * (payload_size // 64 + 1))[:payload_size]
+    return bytes(rng.getrandbits(8) for _ in range(payload_size))
+
+
 def make_dc_id(dc_num: int) -> str:
     """Generate data center ID: dc_01, dc_02, ..."""
     return f"dc_{dc_num:02d}"
@@ -299,6 +328,7 @@ def create_node(
     payload_size: int,
     block: int,
     seed: int,
+    payload_profile: str = "random",
 ) -> NodeEntity:
     """Create a single Node entity with randomized attributes."""
     rng = random.Random(f"{seed}:node:{dc_num}:{node_num}")
@@ -318,8 +348,8 @@ def create_node(
     avail_hours = sample_from_distribution(rng, get_avail_hours_distribution())
     ttl_blocks = sample_ttl_blocks(rng)
 
-    # Generate random payload
-    payload = bytes(rng.getrandbits(8) for _ in range(payload_size))
+    # Generate payload
+    payload = make_payload(rng, payload_size, payload_profile)
     
     return NodeEntity(
         entity_key=entity_key,
@@ -345,6 +375,7 @@ def create_workload(
     payload_size: int,
     block: int,
     seed: int,
+    payload_profile: str = "random",
 ) -> WorkloadEntity:
     """Create a single Workload entity with randomized attributes."""
     rng = random.Random(f"{seed}:workload:{dc_num}:{workload_num}")
@@ -369,8 +400,8 @@ def create_workload(
     else:
         assigned_node = ""
     
-    # Generate random payload
-    payload = bytes(rng.getrandbits(8) for _ in range(payload_size))
+    # Generate payload
+    payload = make_payload(rng, payload_size, payload_profile)
     
     return WorkloadEntity(
         entity_key=entity_key,
@@ -400,14 +431,22 @@ def generate_nodes(
     payload_size: int,
     start_block: int,
     seed: int,
+    payload_profile: str = "random",
 ) -> Iterator[NodeEntity]:
-    """Generate all Node entities across all data centers."""
+    """
+    Generate all Node entities across all data centers.
+    
+    Each entity is its own transaction (op 0); nodes come first in their block.
+    """
     node_counter = 0
     current_block = start_block
 
     for dc_num in range(1, num_datacenters + 1):
         for node_num in range(1, nodes_per_dc + 1):
-            yield create_node(dc_num, node_num, payload_size, current_block, seed)
+            node = create_node(dc_num, node_num, payload_size, current_block, seed, payload_profile)
+            node.tx_index = node_counter
+            node.sequence = node_counter
+            yield node
 
             node_counter += 1
             if node_counter >= nodes_per_block:
@@ -423,17 +462,30 @@ def generate_workloads(
     payload_size: int,
     start_block: int,
     seed: int,
+    payload_profile: str = "random",
+    nodes_per_block: int = DEFAULT_NODE_UPDATES_PER_BLOCK,
 ) -> Iterator[WorkloadEntity]:
-    """Generate all Workload entities across all data centers."""
+    """
+    Generate all Workload entities across all data centers.
+    
+    Each entity is its own transaction (op 0), following the nodes created in the
+    same block (see generate_nodes).
+    """
     workloads_per_dc = int(nodes_per_dc * workloads_per_node)
+    total_nodes = num_datacenters * nodes_per_dc
     workload_counter = 0
     current_block = start_block
     
     for dc_num in range(1, num_datacenters + 1):
         for workload_num in range(1, workloads_per_dc + 1):
-            yield create_workload(
-                dc_num, workload_num, nodes_per_dc, payload_size, current_block, seed
+            workload = create_workload(
+                dc_num, workload_num, nodes_per_dc, payload_size, current_block, seed, payload_profile
             )
+            # Nodes created in this block precede its workloads
+            block_nodes = min(nodes_per_block, max(0, total_nodes - (current_block - start_block) * nodes_per_block))
+            workload.tx_index = block_nodes + workload_counter
+            workload.sequence = block_nodes + workload_counter
+            yield workload
 
             workload_counter += 1
             if workload_counter >= workloads_per_block:
@@ -758,6 +810,7 @@ def generate_all_nodes(
     seed: int,
     creator_address: str = "0x0000000000000000000000000000000000dc0001",
     batch_size: int = 1000000,
+    payload_profile: str = "random",
 ) -> int:
     """
     Generate and insert all Node entities.
@@ -772,7 +825,9 @@ def generate_all_nodes(
     count = 0
     start_time = time.time()
     
-    for node in generate_nodes(num_datacenters, nodes_per_dc, nodes_per_block, payload_size, start_block, seed):
+    for node in generate_nodes(
+        num_datacenters, nodes_per_dc, nodes_per_block, payload_size, start_block, seed, payload_profile
+    ):
         inserts = node_to_sql_inserts(node, creator_address)
         for sql, params in inserts:
             cursor.execute(sql, params)
@@ -802,6 +857,8 @@ def generate_all_workloads(
     seed: int,
     creator_address: str = "0x0000000000000000000000000000000000dc0002",
     batch_size: int = 1000,
+    payload_profile: str = "random",
+    nodes_per_block: int = DEFAULT_NODE_UPDATES_PER_BLOCK,
 ) -> int:
     """
     Generate and insert all Workload entities.
@@ -818,7 +875,8 @@ def generate_all_workloads(
     start_time = time.time()
     
     for workload in generate_workloads(
-        num_datacenters, nodes_per_dc, workloads_per_node, workloads_per_block, payload_size, start_block, seed
+        num_datacenters, nodes_per_dc, workloads_per_node, workloads_per_block, payload_size, start_block, seed,
+        payload_profile, nodes_per_block,
     ):
         inserts = workload_to_sql_inserts(workload, creator_address)
         for sql, params in inserts:
@@ -882,6 +940,12 @@ def main():
         default=10000,
         help="Payload size in bytes per entity (default: 10000)"
     )
+    parser.add_argument(
+        "--payload-profile",
+        choices=PAYLOAD_PROFILES,
+        default="random",
+        help="Payload content: random (incompressible), zero, json (text) or repeat (default: random)"
+    )
     parser.add_argument(
         "--seed", "-s",
         type=int,
@@ -936,7 +1000,7 @@ def main():
     print(f"Data centers:      {args.datacenters}")
     print(f"Nodes per DC:      {args.nodes_per_dc:,}")
     print(f"Workloads/node:    {args.workloads_per_node}")
-    print(f"Payload size:      {args.payload_size:,} bytes")
+    print(f"Payload size:      {args.payload_size:,} bytes ({args.payload_profile})")
     print(f"Seed:              {args.seed}")
     print()
     
@@ -983,6 +1047,7 @@ def main():
         start_block=start_block,
         seed=args.seed,
         batch_size=args.batch_size,
+        payload_profile=args.payload_profile,
     )
     
     print()
@@ -997,6 +1062,8 @@ def main():
         start_block=start_block,
         seed=args.seed,
         batch_size=args.batch_size,
+        payload_profile=args.payload_profile,
+        nodes_per_block=args.nodes_per_block,
     )
     
     # Update last_block
@@ -0,0 +1,220 @@
+"""
+Import a JSONL dump (from export_dc_db.py) block-by-block and measure ingest throughput.
+
+Entities are written in file order as new blocks of --entities-per-block entities,
+starting after the last block of the target. Each entity keeps its TTL
+(expires_at - from_block) and attributes; the block-dependent system attributes
+($createdAtBlock, $expiration, $txIndex, $opIndex, $sequence) are rewritten for
+the new block.
+
+Usage:
+    # Load a dump into a new database
+    uv run python -m src.db.import_dc_db data/dc_blocks.jsonl --output data/dc_imported.db
+
+    # Replay a dump on top of a copy of an existing database, 100 entities per block
+    uv run python -m src.db.import_dc_db data/dc_blocks.jsonl \
+        --input data/dc_seed.db \
+        --output data/dc_replayed.db \
+        --entities-per-block 100
+"""
+
+import argparse
+import base64
+import json
+import os
+import sys
+import time
+from datetime import datetime
+from typing import Any, Iterator
+
+from .append_dc_data import (
+    INDEX_SQL,
+    apply_delete,
+    configure_memory,
+    get_max_block,
+    init_database,
+)
+
+
+def read_blocks(path: str, entities_per_block: int) -> Iterator[list[dict[str, Any]]]:
+    """Yield the dump's entities in groups of entities_per_block."""
+    block: list[dict[str, Any]] = []
+    with open(path) as f:
+        for line in f:
+            if not line.strip():
+                continue
+            block.append(json.loads(line))
+            if len(block) == entities_per_block:
+                yield block
+                block = []
+    if block:
+        yield block
+
+
+def entity_to_sql_inserts(entity: dict[str, Any], block: int, position: int) -> list[tuple[str, tuple]]:
+    """
+    Convert an exported entity to SQL INSERT statements for the given block.
+
+    position is the entity's index in the block; each entity is its own transaction.
+    """
+    entity_key = bytes.fromhex(entity["entity_key"])
+    expires_at_block = block + entity["expires_at"] - entity["from_block"]
+
+    string_attrs = entity["string_attributes"]
+    numeric_attrs = {
+        **entity["numeric_attributes"],
+        "$createdAtBlock": block,
+        "$expiration": expires_at_block,
+        "$opIndex": 0,
+        "$sequence": position,
+        "$txIndex": position,
+    }
+
+    inserts = []
+    for key, value in string_attrs.items():
+        inserts.append((
+            """INSERT INTO string_attributes
+               (entity_key, from_block, to_block, key, value)
+               VALUES (?, ?, ?, ?, ?)""",
+            (entity_key, block, expires_at_block, key, value)
+        ))
+    for key, value in numeric_attrs.items():
+        inserts.append((
+            """INSERT INTO numeric_attributes
+               (entity_key, from_block, to_block, key, value)
+               VALUES (?, ?, ?, ?, ?)""",
+            (entity_key, block, expires_at_block, key, value)
+        ))
+    inserts.append((
+        """INSERT INTO payloads
+           (entity_key, from_block, to_block, payload, content_type, string_attributes, numeric_attributes)
+           VALUES (?, ?, ?, ?, ?, ?, ?)""",
+        (entity_key, block, expires_at_block, base64.b64decode(entity["payload"]), entity["content_type"],
+         json.dumps(string_attrs), json.dumps(numeric_attrs))
+    ))
+    return inserts
+
+
+def main():
+    parser = argparse.ArgumentParser(
+        description="Import a JSONL dump block-by-block and measure ingest throughput"
+    )
+    parser.add_argument(
+        "dump",
+        type=str,
+        help="JSONL dump written by export_dc_db.py"
+    )
+    parser.add_argument(
+        "--input", "-i",
+        type=str,
+        default=None,
+        help="Input database to import on top of (optional, creates empty DB if not specified)"
+    )
+    parser.add_argument(
+        "--output", "-o",
+        type=str,
+        required=True,
+        help="Output database path"
+    )
+    parser.add_argument(
+        "--entities-per-block", "-e",
+        type=int,
+        default=12,
+        help="Entities written per block (default: 12)"
+    )
+    parser.add_argument(
+        "--batch-size",
+        type=int,
+        default=1,
+        help="Commit every N blocks (default: 1)"
+    )
+    parser.add_argument(
+        "--memory", "-m",
+        type=int,
+        default=2,
+        help="Memory allocation in GB for SQLite cache (default: 2)"
+    )
+
+    args = parser.parse_args()
+
+    if not os.path.exists(args.dump):
+        print(f"Error: Dump not found: {args.dump}", file=sys.stderr)
+        return 1
+    if args.entities_per_block < 1 or args.batch_size < 1:
+        print("Error: --entities-per-block and --batch-size must be >= 1", file=sys.stderr)
+        return 1
+
+    print("=" * 60)
+    print("Data Center JSONL Importer")
+    print("=" * 60)
+    print(f"Dump:               {args.dump}")
+    print(f"Input:              {args.input or '(empty database)'}")
+    print(f"Output:             {args.output}")
+    print(f"Entities per block: {args.entities_per_block}")
+    print()
+
+    conn = init_database(args.output, args.input)
+    conn.executescript(INDEX_SQL)
+    configure_memory(conn, args.memory)
+
+    # Entities already in the target are replaced by a new version (closing the old one)
+    replace_existing = bool(args.input)
+    start_block = get_max_block(conn) + 1
+    print(f"Starting block:     {start_block}")
+    print()
+
+    cursor = conn.cursor()
+    entity_count = 0
+    payload_bytes = 0
+    block_count = 0
+    block_num = start_block - 1
+    start_time = time.time()
+
+    for entities in read_blocks(args.dump, args.entities_per_block):
+        block_num = start_block + block_count
+        for position, entity in enumerate(entities):
+            if replace_existing:
+                apply_delete(cursor, bytes.fromhex(entity["entity_key"]), block_num)
+            inserts = entity_to_sql_inserts(entity, block_num, position)
+            for sql, params in inserts:
+                cursor.execute(sql, params)
+            payload_bytes += len(inserts[-1][1][3])
+        entity_count += len(entities)
+        block_count += 1
+
+        if block_count % args.batch_size == 0:
+            conn.commit()
+        if block_count % 100 == 0:
+            elapsed = time.time() - start_time
+            rate = entity_count / elapsed if elapsed > 0 else 0
+            print(f"  Block {block_count:,} - {entity_count:,} entities - {rate:.0f}/sec - "
+                  f"{datetime.now().strftime('%H:%M:%S')}")
+
+    cursor.execute(
+        "INSERT OR REPLACE INTO last_block (id, block) VALUES (1, ?)",
+        (block_num,)
+    )
+    conn.commit()
+    total_time = time.time() - start_time
+    conn.close()
+
+    print()
+    print("=" * 60)
+    print("Summary")
+    print("=" * 60)
+    print(f"Blocks written:    {block_count:,}")
+    print(f"Block range:       {start_block:,} - {block_num:,}")
+    print(f"Entities imported: {entity_count:,}")
+    print(f"Payload imported:  {payload_bytes / (1024**2):.1f} MB")
+    print(f"Total time:        {total_time:.1f}s")
+    if total_time > 0:
+        print(f"Rate:              {entity_count / total_time:.0f} entities/sec, "
+              f"{payload_bytes / (1024**2) / total_time:.1f} MB/sec, "
+              f"{block_count / total_time:.1f} blocks/sec")
+    print(f"Database size:     {os.path.getsize(args.output) / (1024**3):.2f} GB")
+    print(f"Output:            {args.output}")
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
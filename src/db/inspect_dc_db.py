@@ -140,10 +140,63 @@ def get_random_entity(conn: sqlite3.Connection, entity_type: str) -> dict | None
     return result
 
 
-def inspect_database(db_path: str) -> dict:
+def get_storage_stats(
+    conn: sqlite3.Connection,
+    db_path: str,
+    row_counts: dict[str, int] | None = None,
+    table_sizes: bool = True,
+) -> dict:
+    """
+    WAL size, page statistics and per-table row counts and on-disk sizes.
+    
+    Table and index sizes come from the dbstat virtual table, which reads every
+    page; if SQLite was built without it, or table_sizes is False ("tables_skipped"
+    set), "tables" is None. Row counts already known (row_counts, by table name) are not counted again.
+    """
+    cursor = conn.cursor()
+    wal_path = db_path + "-wal"
+    stats = {
+        "wal_size": os.path.getsize(wal_path) if os.path.exists(wal_path) else 0,
+        "page_size": cursor.execute("PRAGMA page_size").fetchone()[0],
+        "page_count": cursor.execute("PRAGMA page_count").fetchone()[0],
+        "freelist_count": cursor.execute("PRAGMA freelist_count").fetchone()[0],
+    }
+    
+    if not table_sizes:
+        stats["tables"] = None
+        stats["tables_skipped"] = True
+        return stats
+    try:
+        cursor.execute("SELECT name, SUM(pgsize) FROM dbstat GROUP BY name")
+        sizes = dict(cursor.fetchall())
+    except sqlite3.OperationalError:
+        stats["tables"] = None
+        return stats
+    
+    cursor.execute("""
+        SELECT type, name, tbl_name FROM sqlite_master
+        WHERE type IN ('table', 'index') ORDER BY tbl_name, type DESC, name
+    """)
+    tables = {}
+    for obj_type, name, tbl_name in cursor.fetchall():
+        if obj_type == "table":
+            rows = (row_counts or {}).get(name)
+            if rows is None:
+                rows = conn.execute(f'SELECT COUNT(*) FROM "{name}"').fetchone()[0]
+            tables[name] = {"rows": rows, "size": sizes.get(name, 0), "indexes": {}}
+        elif tbl_name in tables:
+            tables[tbl_name]["indexes"][name] = sizes.get(name, 0)
+    stats["tables"] = tables
+    return stats
+
+
+def inspect_database(db_path: str, table_sizes: bool = True) -> dict:
     """
     Inspect a database and return statistics.
     
+    table_sizes=False skips the per-table sizes (a scan of every page, slow on
+    large databases).
+    
     Returns dict with:
         - file_size: Size in bytes
         - datacenters: List of DC IDs
@@ -320,6 +373,14 @@ def inspect_database(db_path: str) -> dict:
         stats["nodes_per_block"] = 0.0
         stats["workloads_per_block"] = 0.0
     
+    # Storage breakdown
+    row_counts = {
+        "string_attributes": stats["total_str_attrs"],
+        "numeric_attributes": stats["total_num_attrs"],
+        "payloads": stats["total_payloads"],
+    }
+    stats.update(get_storage_stats(conn, db_path, row_counts, table_sizes))
+    
     # Get random example entities
     stats["example_node"] = get_random_entity(conn, "node")
     stats["example_workload"] = get_random_entity(conn, "workload")
@@ -413,6 +474,25 @@ def print_report(db_path: str, stats: dict):
     print(f"Total rows:            {stats['total_rows']:,}")
     print()
     
+    print("--- Storage ---")
+    print(f"WAL size:              {format_size(stats['wal_size'])}")
+    print(f"Pages:                 {stats['page_count']:,} x {stats['page_size']:,} bytes "
+          f"({stats['freelist_count']:,} free)")
+    if stats.get("tables_skipped"):
+        print("Table sizes:           skipped (--no-table-sizes)")
+    elif stats["tables"] is None:
+        print("Table sizes:           unavailable (SQLite built without dbstat)")
+    else:
+        print(f"{'Table / index':<44} {'Rows':>12} {'Size':>12} {'Share':>7}")
+        total = sum(t["size"] + sum(t["indexes"].values()) for t in stats["tables"].values())
+        for name, table in stats["tables"].items():
+            share = table["size"] / total * 100 if total else 0
+            print(f"{name:<44} {table['rows']:>12,} {format_size(table['size']):>12} {share:>6.1f}%")
+            for index_name, size in table["indexes"].items():
+                share = size / total * 100 if total else 0
+                print(f"  {index_name:<42} {'':>12} {format_size(size):>12} {share:>6.1f}%")
+    print()
+    
     print("--- Example Entities ---")
     if stats.get("example_node"):
         print("Example Node:")
@@ -444,11 +524,16 @@ def main():
         action="store_true",
         help="Output as JSON instead of formatted text"
     )
+    parser.add_argument(
+        "--no-table-sizes",
+        action="store_true",
+        help="Skip per-table and index sizes (dbstat reads every page; slow on large databases)"
+    )
     
     args = parser.parse_args()
     
     try:
-        stats = inspect_database(args.database)
+        stats = inspect_database(args.database, table_sizes=not args.no_table_sizes)
         
         if args.json:
             import json
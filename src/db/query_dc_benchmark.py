@@ -14,6 +14,30 @@ Usage:
         --database data/dc_seed_2x.db \
         --current-block 500 \
         --queries 5000
+
+    # Time-boxed run of a fixed, named query set
+    uv run python -m src.db.query_dc_benchmark \
+        --database data/dc_seed_2x.db \
+        --query-set queries/dc_regression.json \
+        --duration 5m
+
+    # Record every SQL statement as JSONL for replay
+    uv run python -m src.db.query_dc_benchmark \
+        --database data/dc_seed_2x.db \
+        --queries 1000 \
+        --trace data/benchmark.trace.jsonl
+
+    # Compare native multi-attribute AND against client-side fan-out
+    uv run python -m src.db.query_dc_benchmark \
+        --database data/dc_seed_2x.db \
+        --queries 5000 \
+        --fanout
+
+    # Mixed read/write: 10% of operations are entity writes (writes into the database!)
+    uv run python -m src.db.query_dc_benchmark \
+        --database data/dc_seed_2x_copy.db \
+        --queries 5000 \
+        --write-ratio 0.1
 """
 
 import argparse
@@ -22,12 +46,28 @@ import json
 import os
 import random
 import sqlite3
+import sys
+import threading
 import time
 import uuid
+from concurrent.futures import ThreadPoolExecutor
 from dataclasses import dataclass, asdict
 from datetime import datetime
 from enum import Enum
-from typing import Any, TextIO
+from typing import Any, Callable, TextIO
+
+from .append_dc_data import (
+    TUNABLE_PRAGMAS,
+    active_pragmas,
+    apply_pragmas,
+    create_run_dir,
+    database_size,
+    generate_blocks,
+    node_to_sql_inserts,
+    parse_pragma,
+    workload_fingerprint,
+    workload_to_sql_inserts,
+)
 
 
 # =============================================================================
@@ -55,10 +95,36 @@ SAMPLE_SIZE_KEYS = 1000
 REGIONS = ["eu-west", "us-east", "asia-pac"]
 VM_TYPES = ["cpu", "gpu", "gpu_large"]
 
+# Columns of the per-query CSV log
+LOG_HEADER = ["timestamp", "query_type", "latency_ms", "queued_ms", "row_count", "params", "fingerprint"]
+
 # Default result set limits
 DEFAULT_NODE_LIMIT = 100
 DEFAULT_WORKLOAD_LIMIT = 100
 
+# Number of payload rows sampled for the dataset summary
+SAMPLE_SIZE_PAYLOADS = 1000
+
+# Number of single-attribute predicates in a node filter (one connection each in fan-out mode)
+FANOUT_PREDICATES = 7
+
+# Block shape used by the background writer in mixed read/write mode
+WRITER_NODES_PER_BLOCK = 2
+WRITER_WORKLOADS_PER_NODE = 5
+WRITER_PAYLOAD_SIZE = 10000
+WRITER_DC_NUM = 99  # Keeps writer ids disjoint from generated data centers
+WRITER_CREATOR = "0x0000000000000000000000000000000000dc0099"
+
+# PRAGMAs that can be overridden per connection (journal_mode and page_size change the
+# database file, so they are set when the database is generated, not when it is queried)
+CONNECTION_PRAGMAS = [name for name in TUNABLE_PRAGMAS if name not in ("journal_mode", "page_size")]
+
+# Point lookup projections: everything, attributes without payload, or the key only
+PROJECTION_FULL = "full"
+PROJECTION_ATTRIBUTES = "attributes"
+PROJECTION_KEYS = "keys"
+PROJECTIONS = [PROJECTION_FULL, PROJECTION_ATTRIBUTES, PROJECTION_KEYS]
+
 
 class QueryType(Enum):
     """Query type identifiers."""
@@ -68,6 +134,7 @@ class QueryType(Enum):
     NODE_FILTER = "node_filter"
     WORKLOAD_SIMPLE = "workload_simple"
     WORKLOAD_SPECIFIC = "workload_specific"
+    NODE_FILTER_FANOUT = "node_filter_fanout"
 
 
 # =============================================================================
@@ -96,6 +163,21 @@ class QueryResult:
     row_count: int
     success: bool
     error: str | None = None
+    name: str | None = None             # Query set entry name (query set mode only)
+    queued_ms: float = 0.0              # Wait past the scheduled start (paced runs only)
+    
+    @property
+    def response_ms(self) -> float:
+        """Latency seen by a client issuing queries at the target rate (queued + service)."""
+        return self.queued_ms + self.latency_ms
+
+
+@dataclass
+class NamedQuery:
+    """A query set entry: fixed query type plus (partial) parameter overrides."""
+    name: str
+    query_type: QueryType
+    params: dict[str, Any]
 
 
 # =============================================================================
@@ -181,7 +263,7 @@ class QueryGenerator:
         elif query_type == QueryType.POINT_MISS:
             params.entity_id = self.generate_random_uuid()
         
-        elif query_type == QueryType.NODE_FILTER:
+        elif query_type in (QueryType.NODE_FILTER, QueryType.NODE_FILTER_FANOUT):
             params.region = self.rng.choice(REGIONS)
             params.vm_type = self.rng.choice(VM_TYPES)
             params.min_cpu = self.rng.choice([1, 2, 4, 8])
@@ -199,6 +281,75 @@ class QueryGenerator:
         return params
 
 
+# =============================================================================
+# Call Tracing
+# =============================================================================
+
+class CallTracer:
+    """Writes one JSON line per SQL statement issued by the executor."""
+    
+    def __init__(self, trace_file: TextIO):
+        self.trace_file = trace_file
+        self._lock = threading.Lock()
+    
+    def record(
+        self,
+        query_type: QueryType,
+        sql: str,
+        args: tuple,
+        duration_ms: float,
+        result_rows: int,
+    ) -> None:
+        """Append a trace record (thread-safe, fan-out queries trace concurrently)."""
+        entry = {
+            "timestamp": datetime.now().isoformat(),
+            "query_type": query_type.value,
+            "sql": " ".join(sql.split()),
+            "args": [a.hex() if isinstance(a, bytes) else a for a in args],
+            "duration_ms": round(duration_ms, 3),
+            "result_rows": result_rows,
+        }
+        with self._lock:
+            self.trace_file.write(json.dumps(entry) + "\n")
+
+
+class TracingCursor:
+    """
+    Cursor wrapper timing each execute() through its fetch.
+    
+    Only the execute/fetchone/fetchall calls used by the executor are wrapped.
+    """
+    
+    def __init__(self, cursor: sqlite3.Cursor, tracer: CallTracer, query_type: QueryType):
+        self._cursor = cursor
+        self._tracer = tracer
+        self._query_type = query_type
+        self._sql = ""
+        self._args: tuple = ()
+        self._start = 0.0
+    
+    def execute(self, sql: str, args: tuple = ()) -> "TracingCursor":
+        self._sql = sql
+        self._args = args
+        self._start = time.perf_counter()
+        self._cursor.execute(sql, args)
+        return self
+    
+    def fetchone(self) -> Any:
+        row = self._cursor.fetchone()
+        self._finish(1 if row else 0)
+        return row
+    
+    def fetchall(self) -> list[Any]:
+        rows = self._cursor.fetchall()
+        self._finish(len(rows))
+        return rows
+    
+    def _finish(self, result_rows: int) -> None:
+        duration_ms = (time.perf_counter() - self._start) * 1000
+        self._tracer.record(self._query_type, self._sql, self._args, duration_ms, result_rows)
+
+
 # =============================================================================
 # Query Executor
 # =============================================================================
@@ -213,36 +364,105 @@ class QueryExecutor:
         log_file: TextIO | None = None,
         node_limit: int = DEFAULT_NODE_LIMIT,
         workload_limit: int = DEFAULT_WORKLOAD_LIMIT,
+        fanout_conns: list[sqlite3.Connection] | None = None,
+        tracer: CallTracer | None = None,
+        fingerprint: str = "",
+        projection: str = PROJECTION_FULL,
+        log_sample: dict[str, float] | None = None,
+        split_log_path: str | None = None,
     ):
         self.conn = conn
         self.current_block = current_block
         self.log_file = log_file
         self.node_limit = node_limit
         self.workload_limit = workload_limit
+        self.tracer = tracer
+        self.fingerprint = fingerprint
+        self.projection = projection
+        # Result rows of the most recent filter query (used by the verifier)
+        self.last_rows: list[Any] = []
+        # First query executed on this connection (cold cache latency)
+        self.first_result: QueryResult | None = None
+        self.query_count = 0
+        # One connection per single-attribute predicate for fan-out queries
+        self.fanout_conns = fanout_conns or []
+        self.fanout_pool: ThreadPoolExecutor | None = None
+        if self.fanout_conns:
+            self.fanout_pool = ThreadPoolExecutor(max_workers=len(self.fanout_conns))
+        # Per-type fraction of queries to log (types not listed are always logged)
+        self.log_sample = log_sample or {}
+        self._log_rng = random.Random(f"{fingerprint}:log-sample")
+        self.logged_counts: dict[QueryType, int] = {}
+        # With split_log_path, each query type is logged to its own file (opened on first use)
+        self.split_log_path = split_log_path
+        self.split_log_files: dict[QueryType, TextIO] = {}
+        self._csv_writers: dict[QueryType, csv.writer] = {}
         self.csv_writer: csv.writer | None = None
         if log_file:
             self.csv_writer = csv.writer(log_file)
             # Write header
-            self.csv_writer.writerow(["timestamp", "query_type", "latency_ms", "row_count", "params"])
+            self.csv_writer.writerow(LOG_HEADER)
+    
+    def _csv_writer_for(self, query_type: QueryType) -> "csv.writer | None":
+        """CSV writer for a query type: the shared log or the type's own file."""
+        if not self.split_log_path:
+            return self.csv_writer
+        if query_type not in self._csv_writers:
+            stem, ext = os.path.splitext(self.split_log_path)
+            log_file = open(f"{stem}.{query_type.value}{ext}", "w", newline="")
+            self.split_log_files[query_type] = log_file
+            self._csv_writers[query_type] = csv.writer(log_file)
+            self._csv_writers[query_type].writerow(LOG_HEADER)
+        return self._csv_writers[query_type]
+    
+    def close_logs(self) -> None:
+        """Close per-type log files opened in split mode."""
+        for log_file in self.split_log_files.values():
+            log_file.close()
+    
+    def _cursor(
+        self,
+        query_type: QueryType,
+        conn: sqlite3.Connection | None = None,
+    ) -> sqlite3.Cursor | TracingCursor:
+        """Return a cursor, wrapped for call tracing if a tracer is configured."""
+        cursor = (conn or self.conn).cursor()
+        if self.tracer:
+            return TracingCursor(cursor, self.tracer, query_type)
+        return cursor
     
     def _log_query(self, query_type: QueryType, result: QueryResult, params: QueryParams) -> None:
-        """Log query execution to CSV file."""
-        if self.csv_writer:
+        """Log query execution to CSV file (subject to per-type sampling)."""
+        if not self.csv_writer and not self.split_log_path:
+            return
+        sample_rate = self.log_sample.get(query_type.value, 1.0)
+        if sample_rate < 1.0 and self._log_rng.random() >= sample_rate:
+            return
+        csv_writer = self._csv_writer_for(query_type)
+        self.logged_counts[query_type] = self.logged_counts.get(query_type, 0) + 1
+        if csv_writer:
             # Convert params to dict, excluding None values
             params_dict = {k: v for k, v in asdict(params).items() if v is not None}
             # Convert entity_key bytes to hex if present
             if "entity_key" in params_dict and params_dict["entity_key"]:
                 params_dict["entity_key"] = params_dict["entity_key"].hex()
-            self.csv_writer.writerow([
+            csv_writer.writerow([
                 datetime.now().isoformat(),
                 query_type.value,
                 f"{result.latency_ms:.3f}",
+                f"{result.queued_ms:.3f}",
                 result.row_count,
-                json.dumps(params_dict)
+                json.dumps(params_dict),
+                self.fingerprint,
             ])
     
-    def execute(self, query_type: QueryType, params: QueryParams) -> QueryResult:
-        """Execute a query and return the result with timing."""
+    def execute(self, query_type: QueryType, params: QueryParams, queued_ms: float = 0.0) -> QueryResult:
+        """
+        Execute a query and return the result with timing.
+        
+        queued_ms (time the query waited past its scheduled start in paced runs) is
+        recorded next to the measured (service) latency.
+        """
         result: QueryResult
         try:
             if query_type == QueryType.POINT_BY_ID:
@@ -257,6 +477,8 @@ class QueryExecutor:
                 result = self._execute_workload_simple(params)
             elif query_type == QueryType.WORKLOAD_SPECIFIC:
                 result = self._execute_workload_specific(params)
+            elif query_type == QueryType.NODE_FILTER_FANOUT:
+                result = self._execute_node_filter_fanout(params)
             else:
                 result = QueryResult(
                     query_type=query_type,
@@ -274,14 +496,55 @@ class QueryExecutor:
                 error=str(e)
             )
         
+        if result.success:
+            result.queued_ms = queued_ms
+        if self.first_result is None:
+            self.first_result = result
+        self.query_count += 1
+        
         # Log to CSV if enabled
         self._log_query(query_type, result, params)
         return result
     
+    def _fetch_entity(
+        self,
+        cursor: sqlite3.Cursor | TracingCursor,
+        entity_key: bytes,
+        current_block: int,
+    ) -> int:
+        """Fetch attributes (and the payload unless projected away); return row count."""
+        cursor.execute("""
+            SELECT key, value FROM string_attributes
+            WHERE entity_key = ?
+              AND from_block <= ? AND to_block > ?
+        """, (entity_key, current_block, current_block))
+        str_attrs = cursor.fetchall()
+        
+        cursor.execute("""
+            SELECT key, value FROM numeric_attributes
+            WHERE entity_key = ?
+              AND from_block <= ? AND to_block > ?
+        """, (entity_key, current_block, current_block))
+        num_attrs = cursor.fetchall()
+        
+        if self.projection != PROJECTION_FULL:
+            return len(str_attrs) + len(num_attrs)
+        
+        cursor.execute("""
+            SELECT payload FROM payloads
+            WHERE entity_key = ?
+              AND from_block <= ? AND to_block > ?
+            ORDER BY from_block DESC
+            LIMIT 1
+        """, (entity_key, current_block, current_block))
+        payload = cursor.fetchone()
+        
+        return len(str_attrs) + len(num_attrs) + (1 if payload else 0)
+    
     def _execute_point_by_id(self, params: QueryParams) -> QueryResult:
         """Point lookup by node_id or workload_id."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.POINT_BY_ID)
         
         # Determine if it's a node or workload ID
         id_key = "node_id" if params.entity_id and params.entity_id.startswith("node_") else "workload_id"
@@ -307,32 +570,12 @@ class QueryExecutor:
         
         entity_key = row[0]
         
-        # Step 2: Get all attributes
-        cursor.execute("""
-            SELECT key, value FROM string_attributes
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-        """, (entity_key, params.current_block, params.current_block))
-        str_attrs = cursor.fetchall()
-        
-        cursor.execute("""
-            SELECT key, value FROM numeric_attributes
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-        """, (entity_key, params.current_block, params.current_block))
-        num_attrs = cursor.fetchall()
-        
-        cursor.execute("""
-            SELECT payload FROM payloads
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-            ORDER BY from_block DESC
-            LIMIT 1
-        """, (entity_key, params.current_block, params.current_block))
-        payload = cursor.fetchone()
+        # Step 2: Fetch the entity (per projection)
+        row_count = 1
+        if self.projection != PROJECTION_KEYS:
+            row_count = self._fetch_entity(cursor, entity_key, params.current_block)
         
         latency_ms = (time.perf_counter() - start) * 1000
-        row_count = len(str_attrs) + len(num_attrs) + (1 if payload else 0)
         
         return QueryResult(
             query_type=QueryType.POINT_BY_ID,
@@ -344,7 +587,7 @@ class QueryExecutor:
     def _execute_point_by_key(self, params: QueryParams) -> QueryResult:
         """Direct lookup by entity_key."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.POINT_BY_KEY)
         
         if not params.entity_key:
             return QueryResult(
@@ -355,32 +598,20 @@ class QueryExecutor:
                 error="No entity_key provided"
             )
         
-        # Get all attributes directly
-        cursor.execute("""
-            SELECT key, value FROM string_attributes
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-        """, (params.entity_key, params.current_block, params.current_block))
-        str_attrs = cursor.fetchall()
-        
-        cursor.execute("""
-            SELECT key, value FROM numeric_attributes
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-        """, (params.entity_key, params.current_block, params.current_block))
-        num_attrs = cursor.fetchall()
-        
-        cursor.execute("""
-            SELECT payload FROM payloads
-            WHERE entity_key = ?
-              AND from_block <= ? AND to_block > ?
-            ORDER BY from_block DESC
-            LIMIT 1
-        """, (params.entity_key, params.current_block, params.current_block))
-        payload = cursor.fetchone()
+        if self.projection == PROJECTION_KEYS:
+            # Existence check only, answered from the primary key index
+            cursor.execute("""
+                SELECT entity_key FROM payloads
+                WHERE entity_key = ?
+                  AND from_block <= ? AND to_block > ?
+                LIMIT 1
+            """, (params.entity_key, params.current_block, params.current_block))
+            row_count = 1 if cursor.fetchone() else 0
+        else:
+            # Get all attributes directly
+            row_count = self._fetch_entity(cursor, params.entity_key, params.current_block)
         
         latency_ms = (time.perf_counter() - start) * 1000
-        row_count = len(str_attrs) + len(num_attrs) + (1 if payload else 0)
         
         return QueryResult(
             query_type=QueryType.POINT_BY_KEY,
@@ -392,7 +623,7 @@ class QueryExecutor:
     def _execute_point_miss(self, params: QueryParams) -> QueryResult:
         """Lookup non-existent entity (guaranteed miss)."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.POINT_MISS)
         
         # Try to find by random UUID (should return 0 rows)
         cursor.execute("""
@@ -415,7 +646,7 @@ class QueryExecutor:
     def _execute_node_filter(self, params: QueryParams) -> QueryResult:
         """Find available nodes matching filter criteria."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.NODE_FILTER)
         
         cursor.execute("""
             SELECT DISTINCT sa_status.entity_key
@@ -463,6 +694,7 @@ class QueryExecutor:
         
         rows = cursor.fetchall()
         latency_ms = (time.perf_counter() - start) * 1000
+        self.last_rows = rows
         
         return QueryResult(
             query_type=QueryType.NODE_FILTER,
@@ -471,10 +703,70 @@ class QueryExecutor:
             success=True
         )
     
+    def _execute_node_filter_fanout(self, params: QueryParams) -> QueryResult:
+        """
+        Find available nodes by issuing each predicate as its own query.
+        
+        The single-attribute queries run concurrently on separate connections
+        and the resulting entity_key sets are intersected client-side, then
+        ordered by price and limited like the native node filter.
+        """
+        if not self.fanout_pool:
+            return QueryResult(
+                query_type=QueryType.NODE_FILTER_FANOUT,
+                latency_ms=0,
+                row_count=0,
+                success=False,
+                error="Fan-out connections not configured"
+            )
+        
+        start = time.perf_counter()
+        
+        # (table, key, operator, value) for each single-attribute predicate
+        predicates = [
+            ("string_attributes", "status", "=", "available"),
+            ("string_attributes", "region", "=", params.region),
+            ("string_attributes", "vm_type", "=", params.vm_type),
+            ("numeric_attributes", "cpu_count", ">=", params.min_cpu),
+            ("numeric_attributes", "ram_gb", ">=", params.min_ram),
+            ("numeric_attributes", "avail_hours", ">=", params.min_hours),
+            ("numeric_attributes", "price_hour", "<=", params.max_price),
+        ]
+        
+        def run_predicate(index: int) -> dict[bytes, Any]:
+            table, key, op, value = predicates[index]
+            conn = self.fanout_conns[index % len(self.fanout_conns)]
+            cursor = self._cursor(QueryType.NODE_FILTER_FANOUT, conn)
+            rows = cursor.execute(f"""
+                SELECT entity_key, value FROM {table}
+                WHERE key = ? AND value {op} ?
+                  AND from_block <= ? AND to_block > ?
+            """, (key, value, params.current_block, params.current_block)).fetchall()
+            return {row[0]: row[1] for row in rows}
+        
+        key_sets = list(self.fanout_pool.map(run_predicate, range(len(predicates))))
+        
+        # Intersect starting from the smallest set, keep prices for ordering
+        matching = set(min(key_sets, key=len))
+        for keys in key_sets:
+            matching &= keys.keys()
+        prices = key_sets[-1]
+        rows = [(k,) for k in sorted(matching, key=lambda k: prices[k])[:self.node_limit]]
+        
+        latency_ms = (time.perf_counter() - start) * 1000
+        self.last_rows = rows
+        
+        return QueryResult(
+            query_type=QueryType.NODE_FILTER_FANOUT,
+            latency_ms=latency_ms,
+            row_count=len(rows),
+            success=True
+        )
+    
     def _execute_workload_simple(self, params: QueryParams) -> QueryResult:
         """Find pending workloads (status filter only)."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.WORKLOAD_SIMPLE)
         
         cursor.execute("""
             SELECT DISTINCT sa.entity_key
@@ -496,6 +788,7 @@ class QueryExecutor:
         
         rows = cursor.fetchall()
         latency_ms = (time.perf_counter() - start) * 1000
+        self.last_rows = rows
         
         return QueryResult(
             query_type=QueryType.WORKLOAD_SIMPLE,
@@ -507,7 +800,7 @@ class QueryExecutor:
     def _execute_workload_specific(self, params: QueryParams) -> QueryResult:
         """Find pending workloads matching region and vm_type."""
         start = time.perf_counter()
-        cursor = self.conn.cursor()
+        cursor = self._cursor(QueryType.WORKLOAD_SPECIFIC)
         
         cursor.execute("""
             SELECT DISTINCT sa_status.entity_key
@@ -540,6 +833,7 @@ class QueryExecutor:
         
         rows = cursor.fetchall()
         latency_ms = (time.perf_counter() - start) * 1000
+        self.last_rows = rows
         
         return QueryResult(
             query_type=QueryType.WORKLOAD_SPECIFIC,
@@ -549,10 +843,259 @@ class QueryExecutor:
         )
 
 
+# =============================================================================
+# Result Verifier
+# =============================================================================
+
+class ResultVerifier:
+    """
+    Checks filter query results against a plain table-scan oracle.
+    
+    The oracle reads the attribute tables with NOT INDEXED (full scans, no
+    index involved), evaluates the predicate in Python and compares the key
+    set with what the indexed query returned. Divergences are appended to a
+    JSONL artifact with the exact parameters needed to reproduce them.
+    """
+    
+    # query type -> (string predicates, numeric predicates, order-by numeric key)
+    # Predicates are (attribute key, operator, QueryParams field or literal)
+    PREDICATES: dict[QueryType, tuple[list, list, str | None]] = {
+        QueryType.NODE_FILTER: (
+            [("status", "=", "available"), ("region", "=", "region"), ("vm_type", "=", "vm_type")],
+            [("cpu_count", ">=", "min_cpu"), ("ram_gb", ">=", "min_ram"),
+             ("avail_hours", ">=", "min_hours"), ("price_hour", "<=", "max_price")],
+            "price_hour",
+        ),
+        QueryType.NODE_FILTER_FANOUT: (
+            [("status", "=", "available"), ("region", "=", "region"), ("vm_type", "=", "vm_type")],
+            [("cpu_count", ">=", "min_cpu"), ("ram_gb", ">=", "min_ram"),
+             ("avail_hours", ">=", "min_hours"), ("price_hour", "<=", "max_price")],
+            "price_hour",
+        ),
+        QueryType.WORKLOAD_SIMPLE: (
+            [("status", "=", "pending"), ("type", "=", "workload")],
+            [],
+            None,
+        ),
+        QueryType.WORKLOAD_SPECIFIC: (
+            [("status", "=", "pending"), ("region", "=", "region"), ("vm_type", "=", "vm_type"),
+             ("type", "=", "workload")],
+            [],
+            None,
+        ),
+    }
+    
+    def __init__(self, conn: sqlite3.Connection, max_checks: int, artifact_path: str):
+        self.conn = conn
+        self.max_checks = max_checks
+        self.artifact_path = artifact_path
+        self.checked = 0
+        self.mismatches = 0
+    
+    def wants(self, query_type: QueryType) -> bool:
+        """True if this query type is verifiable and the sample is not full yet."""
+        return query_type in self.PREDICATES and self.checked < self.max_checks
+    
+    @staticmethod
+    def _resolve(params: QueryParams, ref: Any) -> Any:
+        """Predicate values are either QueryParams field names or literals."""
+        return getattr(params, ref) if isinstance(ref, str) and hasattr(params, ref) else ref
+    
+    def _scan(self, table: str, keys: list[str], current_block: int) -> dict[bytes, dict[str, Any]]:
+        """Full scan of valid rows for the given attribute keys: entity_key -> {key: value}."""
+        placeholders = ",".join("?" * len(keys))
+        rows = self.conn.execute(f"""
+            SELECT entity_key, key, value FROM {table} NOT INDEXED
+            WHERE key IN ({placeholders})
+              AND from_block <= ? AND to_block > ?
+        """, (*keys, current_block, current_block)).fetchall()
+        attrs: dict[bytes, dict[str, Any]] = {}
+        for entity_key, key, value in rows:
+            attrs.setdefault(entity_key, {})[key] = value
+        return attrs
+    
+    def expected(self, query_type: QueryType, params: QueryParams) -> dict[bytes, Any]:
+        """Return all matching entity keys mapped to their order-by value (or None)."""
+        string_preds, numeric_preds, order_key = self.PREDICATES[query_type]
+        str_attrs = self._scan("string_attributes", [k for k, _, _ in string_preds], params.current_block)
+        num_attrs = (
+            self._scan("numeric_attributes", [k for k, _, _ in numeric_preds], params.current_block)
+            if numeric_preds else {}
+        )
+        
+        ops = {
+            "=": lambda a, b: a == b,
+            ">=": lambda a, b: a >= b,
+            "<=": lambda a, b: a <= b,
+        }
+        matches: dict[bytes, Any] = {}
+        for entity_key, attrs in str_attrs.items():
+            if not all(
+                key in attrs and ops[op](attrs[key], self._resolve(params, ref))
+                for key, op, ref in string_preds
+            ):
+                continue
+            nums = num_attrs.get(entity_key, {})
+            if not all(
+                key in nums and ops[op](nums[key], self._resolve(params, ref))
+                for key, op, ref in numeric_preds
+            ):
+                continue
+            matches[entity_key] = nums.get(order_key) if order_key else None
+        return matches
+    
+    def verify(self, query_type: QueryType, params: QueryParams, rows: list[Any], limit: int) -> bool:
+        """Compare indexed query rows with the oracle; record an artifact on divergence."""
+        self.checked += 1
+        returned = [row[0] for row in rows]
+        expected = self.expected(query_type, params)
+        
+        problems = []
+        unexpected = [k for k in returned if k not in expected]
+        if unexpected:
+            problems.append(f"{len(unexpected)} returned keys do not satisfy the predicate")
+        if len(set(returned)) != min(limit, len(expected)):
+            problems.append(f"returned {len(set(returned))} keys, oracle expects {min(limit, len(expected))}")
+        
+        # Ordered queries must return the cheapest matches
+        _, _, order_key = self.PREDICATES[query_type]
+        if order_key and returned and not unexpected:
+            worst_returned = max(expected[k] for k in returned)
+            skipped = [v for k, v in expected.items() if k not in set(returned)]
+            if skipped and min(skipped) < worst_returned:
+                problems.append(f"skipped a match with {order_key}={min(skipped)} < {worst_returned}")
+        
+        if not problems:
+            return True
+        
+        self.mismatches += 1
+        params_dict = {k: v for k, v in asdict(params).items() if v is not None}
+        if "entity_key" in params_dict:
+            params_dict["entity_key"] = params_dict["entity_key"].hex()
+        with open(self.artifact_path, "a") as f:
+            f.write(json.dumps({
+                "timestamp": datetime.now().isoformat(),
+                "query_type": query_type.value,
+                "params": params_dict,
+                "limit": limit,
+                "problems": problems,
+                "returned_keys": [k.hex() for k in returned],
+                "expected_keys": [k.hex() for k in expected],
+            }) + "\n")
+        return False
+
+
+# =============================================================================
+# Mixed Workload Writer
+# =============================================================================
+
+class BlockWriter(threading.Thread):
+    """
+    Appends blocks on its own connection while queries run.
+    
+    Writes are throttled so that entity writes make up write_ratio of all
+    operations (reads counted by the reads callable). Each block is one commit.
+    
+    Node and workload numbers (and with them ids and entity keys) are derived from
+    the block number, so a writer started on top of an earlier writer's blocks, e.g.
+    a rerun on the same database, creates new entities instead of the same keys again.
+    """
+    
+    def __init__(
+        self,
+        database: str,
+        start_block: int,
+        write_ratio: float,
+        reads: Callable[[], int],
+        seed: int,
+        pragmas: dict[str, str] | None = None,
+    ):
+        super().__init__(daemon=True)
+        self.database = database
+        self.pragmas = pragmas or {}
+        self.start_block = start_block
+        self.write_ratio = write_ratio
+        self.reads = reads
+        self.seed = seed
+        self.stop_event = threading.Event()
+        self.blocks_written = 0
+        self.entities_written = 0
+        self.commit_latencies_ms: list[float] = []
+        self.final_block = start_block - 1
+        self.error: str | None = None
+    
+    def _writes_allowed(self) -> int:
+        """Entity writes allowed so far for the configured read/write ratio."""
+        return int(self.reads() * self.write_ratio / (1 - self.write_ratio))
+    
+    def run(self) -> None:
+        conn = sqlite3.connect(self.database)
+        try:
+            apply_pragmas(conn, self.pragmas)
+            cursor = conn.cursor()
+            blocks = generate_blocks(
+                num_blocks=2**31,
+                nodes_per_block=WRITER_NODES_PER_BLOCK,
+                workloads_per_node=WRITER_WORKLOADS_PER_NODE,
+                percentage_assigned=0.5,
+                payload_size=WRITER_PAYLOAD_SIZE,
+                start_block=self.start_block,
+                seed=self.seed,
+                dc_num=WRITER_DC_NUM,
+                node_offset=(self.start_block - 1) * WRITER_NODES_PER_BLOCK,
+                workload_offset=(self.start_block - 1) * WRITER_NODES_PER_BLOCK * WRITER_WORKLOADS_PER_NODE,
+            )
+            for block_data in blocks:
+                while self.entities_written >= self._writes_allowed():
+                    if self.stop_event.wait(0.001):
+                        return
+                
+                start = time.perf_counter()
+                for node in block_data.nodes:
+                    for sql, params in node_to_sql_inserts(node, WRITER_CREATOR):
+                        cursor.execute(sql, params)
+                for workload in block_data.workloads:
+                    for sql, params in workload_to_sql_inserts(workload, WRITER_CREATOR):
+                        cursor.execute(sql, params)
+                cursor.execute(
+                    "INSERT OR REPLACE INTO last_block (id, block) VALUES (1, ?)",
+                    (block_data.block_num,)
+                )
+                conn.commit()
+                self.commit_latencies_ms.append((time.perf_counter() - start) * 1000)
+                
+                self.blocks_written += 1
+                self.entities_written += len(block_data.nodes) + len(block_data.workloads)
+                self.final_block = block_data.block_num
+                if self.stop_event.is_set():
+                    return
+        except sqlite3.Error as e:
+            self.error = str(e)
+        finally:
+            conn.close()
+    
+    def stop(self) -> None:
+        self.stop_event.set()
+        self.join()
+
+
 # =============================================================================
 # Benchmark Runner
 # =============================================================================
 
+def service_summary(latencies: list[float]) -> dict[str, float]:
+    """Percentiles of query service times (execution only, without queueing)."""
+    latencies = sorted(latencies)
+    n = len(latencies)
+    return {
+        "p50": latencies[int(n * 0.50)],
+        "p95": latencies[int(n * 0.95)] if n > 1 else latencies[0],
+        "p99": latencies[int(n * 0.99)] if n > 1 else latencies[0],
+        "max": latencies[-1],
+        "avg": sum(latencies) / n,
+    }
+
+
 class BenchmarkRunner:
     """Orchestrates the benchmark execution."""
     
@@ -562,11 +1105,22 @@ class BenchmarkRunner:
         generator: QueryGenerator,
         executor: QueryExecutor,
         query_mix: dict[str, float],
+        fanout: bool = False,
+        verifier: ResultVerifier | None = None,
+        rate: float | None = None,
     ):
         self.conn = conn
         self.generator = generator
         self.executor = executor
         self.query_mix = query_mix
+        self.fanout = fanout
+        self.verifier = verifier
+        # Target queries/sec for the measured phase (None = as fast as possible)
+        self.rate = rate
+        # Fan-out re-runs of node filters, kept out of the mix results and overall stats
+        self.fanout_results: list[QueryResult] = []
+        self.late_queries = 0
+        self.phase_seconds = 0.0
         self._query_types = list(QueryType)
         self._weights = [query_mix.get(qt.value, 0) for qt in self._query_types]
     
@@ -574,6 +1128,43 @@ class BenchmarkRunner:
         """Select a query type based on weighted random selection."""
         return self.generator.rng.choices(self._query_types, weights=self._weights, k=1)[0]
     
+    def _verify(self, query_type: QueryType, params: QueryParams, result: QueryResult) -> None:
+        """Check a successful filter query against the oracle (outside the timed section)."""
+        if not self.verifier or not result.success or not self.verifier.wants(query_type):
+            return
+        limit = (
+            self.executor.node_limit
+            if query_type in (QueryType.NODE_FILTER, QueryType.NODE_FILTER_FANOUT)
+            else self.executor.workload_limit
+        )
+        self.verifier.verify(query_type, params, self.executor.last_rows, limit)
+    
+    def _run_fanout(self, query_type: QueryType, params: QueryParams) -> None:
+        """Re-run a node filter as fan-out with identical params for comparison."""
+        if not self.fanout or query_type != QueryType.NODE_FILTER:
+            return
+        result = self.executor.execute(QueryType.NODE_FILTER_FANOUT, params)
+        self.fanout_results.append(result)
+        self._verify(QueryType.NODE_FILTER_FANOUT, params, result)
+    
+    def _pace(self, i: int, phase_start: float) -> float:
+        """
+        Sleep until the i-th query is due at the target rate (open loop).
+        
+        Returns how far (ms) the query starts behind its schedule (0 when unpaced); it
+        is part of the query's response time, so a stalled run shows up in the tail
+        latency instead of being hidden by the queries it delays (coordinated omission).
+        """
+        if not self.rate:
+            return 0.0
+        scheduled = phase_start + i / self.rate
+        delay = scheduled - time.perf_counter()
+        if delay > 0:
+            time.sleep(delay)
+        elif delay < -0.001:
+            self.late_queries += 1
+        return max(0.0, time.perf_counter() - scheduled) * 1000
+    
     def run(self, num_queries: int, warmup: int = 100) -> list[QueryResult]:
         """Run the benchmark and return results."""
         results: list[QueryResult] = []
@@ -589,23 +1180,98 @@ class BenchmarkRunner:
         # Benchmark phase
         print(f"Running {num_queries} benchmark queries...")
         start_time = time.time()
+        phase_start = time.perf_counter()
         
         for i in range(num_queries):
+            queued_ms = self._pace(i, phase_start)
             query_type = self._select_query_type()
             params = self.generator.generate_params(query_type)
-            result = self.executor.execute(query_type, params)
+            result = self.executor.execute(query_type, params, queued_ms)
             results.append(result)
+            self._verify(query_type, params, result)
+            
+            self._run_fanout(query_type, params)
             
             if (i + 1) % 1000 == 0:
                 elapsed = time.time() - start_time
                 rate = (i + 1) / elapsed
                 print(f"  Progress: {i + 1:,}/{num_queries:,} ({rate:.0f} queries/sec)")
         
+        self.phase_seconds = time.perf_counter() - phase_start
+        return results
+    
+    def run_query_set(
+        self,
+        query_set: list[NamedQuery],
+        duration_s: float | None,
+        num_queries: int,
+        warmup: int = 100,
+    ) -> list[QueryResult]:
+        """
+        Cycle through a fixed query set in order.
+        
+        Runs until duration_s elapses, or for num_queries queries when no
+        duration is given. Parameters not set in the query set are generated.
+        """
+        results: list[QueryResult] = []
+        
+        def next_query(i: int) -> tuple[NamedQuery, QueryParams]:
+            named = query_set[i % len(query_set)]
+            params = self.generator.generate_params(named.query_type)
+            for field, value in named.params.items():
+                setattr(params, field, value)
+            return named, params
+        
+        if warmup > 0:
+            print(f"Running {warmup} warmup queries...")
+            for i in range(warmup):
+                named, params = next_query(i)
+                self.executor.execute(named.query_type, params)
+        
+        if duration_s is not None:
+            print(f"Running query set ({len(query_set)} queries) for {duration_s:.0f}s...")
+        else:
+            print(f"Running query set ({len(query_set)} queries) for {num_queries:,} queries...")
+        start_time = time.time()
+        last_progress = start_time
+        phase_start = time.perf_counter()
+        
+        i = 0
+        while True:
+            if duration_s is not None:
+                if time.time() - start_time >= duration_s:
+                    break
+            elif i >= num_queries:
+                break
+            
+            queued_ms = self._pace(i, phase_start)
+            named, params = next_query(i)
+            result = self.executor.execute(named.query_type, params, queued_ms)
+            result.name = named.name
+            results.append(result)
+            self._verify(named.query_type, params, result)
+            self._run_fanout(named.query_type, params)
+            i += 1
+            
+            now = time.time()
+            if now - last_progress >= 10:
+                last_progress = now
+                rate = i / (now - start_time)
+                print(f"  Progress: {i:,} queries, {now - start_time:.0f}s ({rate:.0f} queries/sec)")
+        
+        self.phase_seconds = time.perf_counter() - phase_start
         return results
     
     @staticmethod
-    def compute_statistics(results: list[QueryResult]) -> dict[str, Any]:
-        """Compute statistics from benchmark results."""
+    def compute_statistics(results: list[QueryResult], elapsed_s: float = 0.0) -> dict[str, Any]:
+        """
+        Compute statistics from benchmark results.
+        
+        Percentiles are over response time (queued + service). When queries were
+        queued (--rate), each type and the overall stats also carry a "service" entry
+        with percentiles of the execution time alone. elapsed_s is the wall-clock time
+        of the measured phase, used for throughput.
+        """
         stats: dict[str, Any] = {
             "total_queries": len(results),
             "successful_queries": sum(1 for r in results if r.success),
@@ -615,14 +1281,18 @@ class BenchmarkRunner:
         
         # Group by query type
         by_type: dict[QueryType, list[float]] = {}
+        by_type_service: dict[QueryType, list[float]] = {}
         by_type_rows: dict[QueryType, list[int]] = {}
         for result in results:
             if result.success:
                 if result.query_type not in by_type:
                     by_type[result.query_type] = []
+                    by_type_service[result.query_type] = []
                     by_type_rows[result.query_type] = []
-                by_type[result.query_type].append(result.latency_ms)
+                by_type[result.query_type].append(result.response_ms)
+                by_type_service[result.query_type].append(result.latency_ms)
                 by_type_rows[result.query_type].append(result.row_count)
+        queued = any(r.queued_ms > 0 for r in results if r.success)
         
         # Compute percentiles for each type
         for query_type, latencies in by_type.items():
@@ -640,9 +1310,12 @@ class BenchmarkRunner:
                     "max": latencies[-1],
                     "avg": sum(latencies) / n,
                 }
+                if queued:
+                    stats["by_type"][query_type.value]["service"] = service_summary(by_type_service[query_type])
         
         # Overall statistics
-        all_latencies = [r.latency_ms for r in results if r.success]
+        all_latencies = [r.response_ms for r in results if r.success]
+        all_service = [r.latency_ms for r in results if r.success]
         all_row_counts = [r.row_count for r in results if r.success]
         if all_latencies:
             all_latencies.sort()
@@ -653,9 +1326,33 @@ class BenchmarkRunner:
                 "p99": all_latencies[int(n * 0.99)] if n > 1 else all_latencies[0],
                 "max": all_latencies[-1],
                 "avg": sum(all_latencies) / n,
-                "total_time_ms": sum(all_latencies),
+                "total_time_ms": sum(all_service),
+                "queries_per_sec": n / elapsed_s if elapsed_s > 0 else 0,
                 "avg_row_count": sum(all_row_counts) / n if all_row_counts else 0,
             }
+            if queued:
+                stats["overall"]["service"] = service_summary(all_service)
+        
+        # Per-name statistics for query set runs
+        by_name: dict[str, list[QueryResult]] = {}
+        for result in results:
+            if result.success and result.name is not None:
+                by_name.setdefault(result.name, []).append(result)
+        if by_name:
+            stats["by_name"] = {}
+            for name, named_results in by_name.items():
+                latencies = sorted(r.response_ms for r in named_results)
+                n = len(latencies)
+                stats["by_name"][name] = {
+                    "query_type": named_results[0].query_type.value,
+                    "count": n,
+                    "avg_rows": sum(r.row_count for r in named_results) / n,
+                    "p50": latencies[int(n * 0.50)],
+                    "p95": latencies[int(n * 0.95)] if n > 1 else latencies[0],
+                    "p99": latencies[int(n * 0.99)] if n > 1 else latencies[0],
+                    "max": latencies[-1],
+                    "avg": sum(latencies) / n,
+                }
         
         return stats
 
@@ -708,16 +1405,42 @@ class Reporter:
         
         # Throughput
         if "overall" in stats:
-            total_time_s = stats["overall"]["total_time_ms"] / 1000
-            queries_per_sec = stats["successful_queries"] / total_time_s if total_time_s > 0 else 0
+            overall = stats["overall"]
             print("--- Throughput ---")
-            print(f"Total query time:   {total_time_s:.2f}s")
-            print(f"Queries/sec:        {queries_per_sec:.1f}")
-            print(f"Avg latency:        {stats['overall']['avg']:.2f}ms")
+            print(f"Total query time:   {overall['total_time_ms'] / 1000:.2f}s")
+            print(f"Queries/sec:        {overall['queries_per_sec']:.1f}")
+            print(f"Avg latency:        {overall['avg']:.2f}ms")
+            if "service" in overall:
+                print(f"Avg service time:   {overall['service']['avg']:.2f}ms (without queueing)")
             print(f"Avg result set:     {stats['overall']['avg_row_count']:.1f} rows")
         
         print()
         
+        # Native AND vs client-side fan-out (fan-out re-runs are not part of the totals above).
+        # Fan-out re-runs are not paced, so they are compared with the native service time.
+        native = stats["by_type"].get(QueryType.NODE_FILTER.value)
+        fanout = stats.get("fanout")
+        if native and fanout:
+            native = native.get("service", native)
+            print("--- Fan-out vs Native AND (node_filter) ---")
+            print(f"{'Percentile':<12} {'Native':>10} {'Fan-out':>10} {'Ratio':>8}")
+            for pct in ["p50", "p95", "p99", "avg"]:
+                ratio = fanout[pct] / native[pct] if native[pct] > 0 else 0
+                print(f"{pct:<12} {native[pct]:>10.2f} {fanout[pct]:>10.2f} {ratio:>7.2f}x")
+            print()
+        
+        # Per-name latency for query set runs
+        if "by_name" in stats:
+            print("--- Query Set Latency (ms) ---")
+            print(f"{'Name':<28} {'Count':>7} {'Rows':>7} {'p50':>8} {'p95':>8} {'p99':>8} {'max':>8}")
+            print("-" * 80)
+            for name, name_stats in stats["by_name"].items():
+                print(f"{name[:28]:<28} {name_stats['count']:>7} {name_stats['avg_rows']:>7.1f} "
+                      f"{name_stats['p50']:>8.2f} {name_stats['p95']:>8.2f} "
+                      f"{name_stats['p99']:>8.2f} {name_stats['max']:>8.2f}")
+            print("-" * 80)
+            print()
+        
         # Query distribution
         print("--- Query Distribution ---")
         for query_type in QueryType:
@@ -727,17 +1450,265 @@ class Reporter:
                 print(f"{query_type.value:<20} {type_stats['count']:>8} ({pct:>5.1f}%)")
         
         print("=" * 60)
+    
+    @staticmethod
+    def print_mmap_comparison(
+        mmap_stats: dict[str, Any],
+        no_mmap_stats: dict[str, Any],
+        no_mmap_first: bool,
+    ) -> None:
+        """Print p50/p95 per query type for the mmap and non-mmap runs."""
+        print()
+        print("--- mmap vs Page Cache Reads (ms) ---")
+        print(f"Pass order:         {'mmap off, then mmap' if no_mmap_first else 'mmap, then mmap off'}")
+        print(f"{'Query Type':<20} {'p50 mmap':>9} {'p50 off':>9} {'p95 mmap':>9} {'p95 off':>9} {'Delta':>8}")
+        print("-" * 70)
+        rows = [(qt.value, mmap_stats["by_type"].get(qt.value), no_mmap_stats["by_type"].get(qt.value))
+                for qt in QueryType if qt != QueryType.NODE_FILTER_FANOUT]
+        rows.append((QueryType.NODE_FILTER_FANOUT.value, mmap_stats.get("fanout"), no_mmap_stats.get("fanout")))
+        rows.append(("OVERALL", mmap_stats.get("overall"), no_mmap_stats.get("overall")))
+        for name, on, off in rows:
+            if not on or not off:
+                continue
+            # Positive delta: mmap is slower at p50
+            delta = (on["p50"] - off["p50"]) / off["p50"] * 100 if off["p50"] > 0 else 0
+            print(f"{name:<20} {on['p50']:>9.2f} {off['p50']:>9.2f} "
+                  f"{on['p95']:>9.2f} {off['p95']:>9.2f} {delta:>+7.1f}%")
+        print("-" * 70)
+    
+    @staticmethod
+    def print_reader_scaling(levels: list[dict[str, Any]]) -> None:
+        """Print throughput and latency per number of concurrent readers."""
+        print()
+        print("--- Reader Scaling ---")
+        print(f"{'Readers':>7} {'Queries':>9} {'QPS':>10} {'Speedup':>8} {'Effic.':>7} "
+              f"{'p50 (ms)':>9} {'p95 (ms)':>9} {'p99 (ms)':>9} {'Blocks':>7}")
+        print("-" * 85)
+        base_qps = levels[0]["qps"] / levels[0]["readers"] if levels else 0
+        for level in levels:
+            speedup = level["qps"] / base_qps if base_qps > 0 else 0
+            print(f"{level['readers']:>7} {level['queries']:>9,} {level['qps']:>10,.0f} {speedup:>7.2f}x "
+                  f"{speedup / level['readers']:>6.0%} {level['p50']:>9.2f} {level['p95']:>9.2f} "
+                  f"{level['p99']:>9.2f} {level['blocks_written']:>7,}")
+        print("-" * 85)
+
+
+# =============================================================================
+# mmap Comparison
+# =============================================================================
+
+def drop_page_cache(path: str) -> None:
+    """Ask the OS to evict the database file from the page cache (best effort, Linux only)."""
+    if not hasattr(os, "posix_fadvise"):
+        return
+    fd = os.open(path, os.O_RDONLY)
+    try:
+        os.posix_fadvise(fd, 0, 0, os.POSIX_FADV_DONTNEED)
+    finally:
+        os.close(fd)
+
+
+def run_no_mmap_pass(
+    database: str,
+    memory_gb: int,
+    current_block: int,
+    query_mix: dict[str, float],
+    seed: int,
+    query_set: list[NamedQuery] | None,
+    duration_s: float | None,
+    num_queries: int,
+    warmup: int,
+    fanout: bool,
+    rate: float | None,
+    executor_options: dict[str, Any],
+    pragmas: dict[str, str] | None = None,
+) -> dict[str, Any]:
+    """
+    Repeat the benchmark on fresh connections with mmap disabled.
+    
+    Mirrors the main pass (seed, query mix or set, fan-out, rate, limits,
+    projection) and returns its statistics, with fan-out stats under "fanout".
+    """
+    conn = sqlite3.connect(database)
+    configure_connection(conn, memory_gb, verbose=False, mmap=False, pragmas=pragmas)
+    fanout_conns: list[sqlite3.Connection] = []
+    if fanout:
+        for _ in range(FANOUT_PREDICATES):
+            fanout_conn = sqlite3.connect(database, check_same_thread=False)
+            configure_connection(fanout_conn, memory_gb, verbose=False, mmap=False, pragmas=pragmas)
+            fanout_conns.append(fanout_conn)
+    
+    generator = QueryGenerator(conn, current_block, seed)
+    executor = QueryExecutor(conn, current_block, fanout_conns=fanout_conns, **executor_options)
+    runner = BenchmarkRunner(conn, generator, executor, query_mix, fanout=fanout, rate=rate)
+    if query_set:
+        results = runner.run_query_set(query_set, duration_s, num_queries, warmup)
+    else:
+        results = runner.run(num_queries, warmup)
+    
+    stats = BenchmarkRunner.compute_statistics(results, runner.phase_seconds)
+    if runner.fanout_results:
+        fanout_stats = BenchmarkRunner.compute_statistics(runner.fanout_results)
+        stats["fanout"] = fanout_stats["by_type"].get(QueryType.NODE_FILTER_FANOUT.value)
+    
+    if executor.fanout_pool:
+        executor.fanout_pool.shutdown()
+    for fanout_conn in fanout_conns:
+        fanout_conn.close()
+    conn.close()
+    return stats
+
+
+# =============================================================================
+# Reader Scaling
+# =============================================================================
+
+def run_reader_level(
+    database: str,
+    memory_gb: int,
+    current_block: int,
+    query_mix: dict[str, float],
+    readers: int,
+    queries_per_reader: int,
+    warmup: int,
+    seed: int,
+    executor_options: dict[str, Any],
+    write_ratio: float = 0.0,
+    pragmas: dict[str, str] | None = None,
+) -> dict[str, Any]:
+    """
+    Run the query mix on `readers` threads, each with its own connection.
+    
+    All readers warm up first and then start together; throughput is measured over
+    the wall time until the last reader finishes. With write_ratio > 0 a BlockWriter
+    appends blocks during the measured phase, continuing after the blocks of earlier
+    levels (its entity ids derive from the block number, so no keys are re-created).
+    
+    Raises RuntimeError if a reader fails; a failure during warmup aborts the start
+    barrier so the other readers and the caller do not wait forever.
+    """
+    query_types = list(QueryType)
+    weights = [query_mix.get(qt.value, 0) for qt in query_types]
+    conns = []
+    generators = []
+    executors = []
+    for reader in range(readers):
+        reader_conn = sqlite3.connect(database, check_same_thread=False)
+        configure_connection(reader_conn, memory_gb, verbose=False, pragmas=pragmas)
+        conns.append(reader_conn)
+        generators.append(QueryGenerator(reader_conn, current_block, seed + reader))
+        executors.append(QueryExecutor(reader_conn, current_block, **executor_options))
+    
+    reader_results: list[list[QueryResult]] = [[] for _ in range(readers)]
+    reader_errors: list[str] = []
+    start_barrier = threading.Barrier(readers + 1)
+    
+    def run_reader(reader: int) -> None:
+        generator, executor = generators[reader], executors[reader]
+        try:
+            for i in range(warmup + queries_per_reader):
+                if i == warmup:
+                    start_barrier.wait()
+                query_type = generator.rng.choices(query_types, weights=weights, k=1)[0]
+                result = executor.execute(query_type, generator.generate_params(query_type))
+                if i >= warmup:
+                    reader_results[reader].append(result)
+            if not queries_per_reader:
+                start_barrier.wait()
+        except threading.BrokenBarrierError:
+            pass  # Another reader failed during warmup
+        except Exception as e:
+            reader_errors.append(f"reader {reader}: {e}")
+            start_barrier.abort()
+    
+    def close_connections() -> None:
+        for reader_conn in conns:
+            reader_conn.close()
+    
+    threads = [threading.Thread(target=run_reader, args=(reader,)) for reader in range(readers)]
+    for thread in threads:
+        thread.start()
+    try:
+        start_barrier.wait()
+    except threading.BrokenBarrierError:
+        for thread in threads:
+            thread.join()
+        close_connections()
+        raise RuntimeError(f"level with {readers} readers failed during warmup: {'; '.join(reader_errors)}")
+    
+    writer = None
+    if write_ratio > 0:
+        writer = BlockWriter(
+            database,
+            start_block=get_current_block(conns[0]) + 1,
+            write_ratio=write_ratio,
+            reads=lambda: sum(executor.query_count for executor in executors) - readers * warmup,
+            seed=seed + readers,
+            pragmas=pragmas,
+        )
+        writer.start()
+    
+    start = time.perf_counter()
+    for thread in threads:
+        thread.join()
+    elapsed = time.perf_counter() - start
+    if writer:
+        writer.stop()
+    close_connections()
+    if reader_errors:
+        raise RuntimeError(f"level with {readers} readers failed: {'; '.join(reader_errors)}")
+    
+    results = [result for results in reader_results for result in results]
+    overall = BenchmarkRunner.compute_statistics(results, elapsed).get("overall", {})
+    return {
+        "readers": readers,
+        "queries": len(results),
+        "qps": len(results) / elapsed if elapsed > 0 else 0,
+        "p50": overall.get("p50", 0.0),
+        "p95": overall.get("p95", 0.0),
+        "p99": overall.get("p99", 0.0),
+        "blocks_written": writer.blocks_written if writer else 0,
+    }
+
+
+def parse_readers(value: str) -> list[int]:
+    """Parse a comma-separated list of reader counts, e.g. 1,2,4,8."""
+    levels = [int(v) for v in value.split(",")]
+    if not levels or min(levels) < 1:
+        raise argparse.ArgumentTypeError("reader counts must be positive integers")
+    return levels
+
+
+def parse_connection_pragma(value: str) -> tuple[str, str]:
+    """Parse a --pragma NAME=VALUE argument (NAME from CONNECTION_PRAGMAS)."""
+    name, setting = parse_pragma(value)
+    if name not in CONNECTION_PRAGMAS:
+        raise argparse.ArgumentTypeError(
+            f"pragma {name} changes the database file, set it with append_dc_data "
+            f"(choose from {', '.join(CONNECTION_PRAGMAS)})"
+        )
+    return name, setting
 
 
 # =============================================================================
 # Database Configuration
 # =============================================================================
 
-def configure_connection(conn: sqlite3.Connection, memory_gb: int) -> None:
-    """Configure SQLite connection for optimal read performance."""
+def configure_connection(
+    conn: sqlite3.Connection,
+    memory_gb: int,
+    verbose: bool = True,
+    mmap: bool = True,
+    pragmas: dict[str, str] | None = None,
+) -> None:
+    """Configure SQLite connection for optimal read performance.
+    
+    With mmap=False pages are read through the page cache only (mmap_size = 0).
+    pragmas (--pragma) override the defaults, except mmap_size when mmap=False.
+    """
     # For read-only workloads: small cache, large mmap
     cache_mb = 256
-    mmap_gb = memory_gb - 1
+    mmap_gb = memory_gb - 1 if mmap else 0
     
     cache_kb = cache_mb * 1024
     conn.execute(f"PRAGMA cache_size = -{cache_kb}")
@@ -747,7 +1718,11 @@ def configure_connection(conn: sqlite3.Connection, memory_gb: int) -> None:
     
     conn.execute("PRAGMA temp_store = MEMORY")
     
-    print(f"Memory config: {cache_mb}MB cache, {mmap_gb}GB mmap")
+    overrides = {name: value for name, value in (pragmas or {}).items() if mmap or name != "mmap_size"}
+    apply_pragmas(conn, overrides)
+    
+    if verbose:
+        print(f"Memory config: {cache_mb}MB cache, {mmap_gb}GB mmap")
 
 
 def get_current_block(conn: sqlite3.Connection) -> int:
@@ -764,6 +1739,185 @@ def get_current_block(conn: sqlite3.Connection) -> int:
     return row[0] if row and row[0] else 1
 
 
+def count_live_entities(conn: sqlite3.Connection, current_block: int) -> int:
+    """Number of entities valid at current_block."""
+    cursor = conn.cursor()
+    cursor.execute("""
+        SELECT COUNT(*) FROM payloads
+        WHERE from_block <= ? AND to_block > ?
+    """, (current_block, current_block))
+    return cursor.fetchone()[0]
+
+
+def summarize_dataset(conn: sqlite3.Connection, current_block: int) -> dict[str, Any]:
+    """
+    Summarize the dataset state the benchmark starts from.
+    
+    Returns dict with:
+        - current_block: Block used for bi-temporal queries
+        - live_entities: Entities valid at current_block
+        - total_payload_rows: All payload versions (including expired)
+        - string_attr_keys / numeric_attr_keys: key -> row count
+        - payload_size_sample: min/p50/p95/max/avg over a random rowid sample
+    """
+    cursor = conn.cursor()
+    summary: dict[str, Any] = {"current_block": current_block}
+    summary["live_entities"] = count_live_entities(conn, current_block)
+    
+    cursor.execute("SELECT COUNT(*), MAX(rowid) FROM payloads")
+    total_rows, max_rowid = cursor.fetchone()
+    summary["total_payload_rows"] = total_rows
+    
+    for table, name in [("string_attributes", "string_attr_keys"), ("numeric_attributes", "numeric_attr_keys")]:
+        cursor.execute(f"SELECT key, COUNT(*) FROM {table} GROUP BY key ORDER BY key")
+        summary[name] = {key: count for key, count in cursor.fetchall()}
+    
+    # Sample payload sizes by random rowid (avoids ORDER BY RANDOM() full scan), joined
+    # through a temp table rather than one bound parameter per rowid
+    sizes: list[int] = []
+    if max_rowid:
+        rng = random.Random(current_block)
+        cursor.execute("CREATE TEMP TABLE IF NOT EXISTS payload_sample (id INTEGER PRIMARY KEY)")
+        cursor.executemany(
+            "INSERT OR IGNORE INTO payload_sample (id) VALUES (?)",
+            [(rng.randint(1, max_rowid),) for _ in range(SAMPLE_SIZE_PAYLOADS)],
+        )
+        cursor.execute("""
+            SELECT LENGTH(p.payload) FROM payload_sample s
+            JOIN payloads p ON p.rowid = s.id
+        """)
+        sizes = sorted(row[0] or 0 for row in cursor.fetchall())
+        cursor.execute("DROP TABLE payload_sample")
+    if sizes:
+        n = len(sizes)
+        summary["payload_size_sample"] = {
+            "samples": n,
+            "min": sizes[0],
+            "p50": sizes[int(n * 0.50)],
+            "p95": sizes[int(n * 0.95)],
+            "max": sizes[-1],
+            "avg": sum(sizes) / n,
+        }
+    
+    return summary
+
+
+def print_dataset_summary(summary: dict[str, Any]) -> None:
+    """Print the dataset summary collected at startup."""
+    print("--- Dataset ---")
+    print(f"Live entities:      {summary['live_entities']:,}")
+    print(f"Payload rows:       {summary['total_payload_rows']:,} (all versions)")
+    print(f"String attr keys:   {len(summary['string_attr_keys'])} "
+          f"({sum(summary['string_attr_keys'].values()):,} rows)")
+    print(f"Numeric attr keys:  {len(summary['numeric_attr_keys'])} "
+          f"({sum(summary['numeric_attr_keys'].values()):,} rows)")
+    sample = summary.get("payload_size_sample")
+    if sample:
+        print(f"Payload size:       p50 {sample['p50']:,} B, p95 {sample['p95']:,} B, "
+              f"max {sample['max']:,} B ({sample['samples']} samples)")
+    print()
+
+
+def load_query_set(path: str) -> list[NamedQuery]:
+    """
+    Load a query set file.
+    
+    Format (JSON):
+        {"queries": [
+            {"name": "gpu_eu_cheap", "type": "node_filter",
+             "params": {"region": "eu-west", "vm_type": "gpu", "max_price": 200}},
+            {"name": "pending_all", "type": "workload_simple"}
+        ]}
+    
+    entity_key params are given as hex strings.
+    """
+    with open(path) as f:
+        data = json.load(f)
+    
+    valid_fields = set(QueryParams.__dataclass_fields__)
+    queries: list[NamedQuery] = []
+    for entry in data.get("queries", []):
+        name = entry["name"]
+        query_type = QueryType(entry["type"])
+        params = dict(entry.get("params", {}))
+        unknown = set(params) - valid_fields
+        if unknown:
+            raise ValueError(f"Query '{name}': unknown params {sorted(unknown)}")
+        if "entity_key" in params:
+            params["entity_key"] = bytes.fromhex(params["entity_key"].removeprefix("0x"))
+        queries.append(NamedQuery(name=name, query_type=query_type, params=params))
+    
+    if not queries:
+        raise ValueError("Query set contains no queries")
+    names = [q.name for q in queries]
+    if len(set(names)) != len(names):
+        raise ValueError("Query set names must be unique")
+    return queries
+
+
+def parse_duration(value: str) -> float:
+    """Parse a duration like '90', '90s', '5m' or '1h' into seconds."""
+    units = {"s": 1, "m": 60, "h": 3600}
+    if value and value[-1] in units:
+        return float(value[:-1]) * units[value[-1]]
+    return float(value)
+
+
+def load_slo(path: str) -> dict[str, Any]:
+    """
+    Load SLO thresholds from a JSON file.
+    
+    Format: {"<group>": {"<stat>": max_ms}, "bytes_per_entity": max_bytes}
+    where group is a query type, "overall" or "write_block", and stat one of
+    p50/p95/p99/max/avg.
+    """
+    with open(path) as f:
+        slo = json.load(f)
+    if not isinstance(slo, dict):
+        raise ValueError("SLO file must contain a JSON object")
+    
+    def is_number(value: Any) -> bool:
+        return isinstance(value, (int, float)) and not isinstance(value, bool)
+    
+    groups = {qt.value for qt in QueryType} | {"overall", "write_block"}
+    for group, limits in slo.items():
+        if group == "bytes_per_entity":
+            if not is_number(limits):
+                raise ValueError("SLO 'bytes_per_entity' must be a number")
+            continue
+        if group not in groups:
+            raise ValueError(f"unknown SLO group '{group}'")
+        if not isinstance(limits, dict):
+            raise ValueError(f"SLO group '{group}' must be an object of stat to max_ms")
+        unknown = set(limits) - {"p50", "p95", "p99", "max", "avg"}
+        if unknown:
+            raise ValueError(f"unknown SLO stats for '{group}': {', '.join(sorted(unknown))}")
+        invalid = [stat for stat, limit in limits.items() if not is_number(limit)]
+        if invalid:
+            raise ValueError(f"SLO limits for '{group}' must be numbers: {', '.join(sorted(invalid))}")
+    return slo
+
+
+def check_slo(slo: dict[str, Any], measured: dict[str, Any]) -> list[tuple[str, float, float | None, bool]]:
+    """
+    Compare measured values against SLO thresholds.
+    
+    Returns (metric, limit, measured, ok) per threshold; a metric without
+    data (measured None) counts as a breach.
+    """
+    checks = []
+    for group, limits in slo.items():
+        if group == "bytes_per_entity":
+            value = measured.get("bytes_per_entity")
+            checks.append((group, limits, value, value is not None and value <= limits))
+            continue
+        group_stats = measured.get(group)
+        for stat, limit in limits.items():
+            value = group_stats.get(stat) if group_stats else None
+            checks.append((f"{group}.{stat}", limit, value, value is not None and value <= limit))
+    return checks
+
+
 # =============================================================================
 # Main Entry Point
 # =============================================================================
@@ -820,6 +1974,17 @@ def main():
         default=None,
         help="Path to CSV log file for query details (e.g., benchmark.log)"
     )
+    parser.add_argument(
+        "--log-sample",
+        type=str,
+        default=None,
+        help='JSON object of per query type log sampling rates, e.g. \'{"point_by_key": 0.01}\' (default: log all)'
+    )
+    parser.add_argument(
+        "--log-split",
+        action="store_true",
+        help="Write one log file per query type (<log stem>.<query_type><ext>) instead of a single --log file"
+    )
     parser.add_argument(
         "--node-limit",
         type=int,
@@ -832,6 +1997,86 @@ def main():
         default=DEFAULT_WORKLOAD_LIMIT,
         help=f"Max result set size for workload filter queries (default: {DEFAULT_WORKLOAD_LIMIT})"
     )
+    parser.add_argument(
+        "--query-set",
+        type=str,
+        default=None,
+        help="JSON file with named queries to cycle through instead of the random mix"
+    )
+    parser.add_argument(
+        "--duration",
+        type=parse_duration,
+        default=None,
+        help="Run the query set for this long, e.g. 300, 90s, 5m (default: --queries count)"
+    )
+    parser.add_argument(
+        "--trace",
+        type=str,
+        default=None,
+        help="Path to JSONL trace file recording every SQL call (statement, args, duration, rows)"
+    )
+    parser.add_argument(
+        "--verify",
+        type=int,
+        default=0,
+        help="Check the first N filter query results against a NOT INDEXED table-scan oracle"
+    )
+    parser.add_argument(
+        "--fanout",
+        action="store_true",
+        help="Also run each node filter as concurrent single-attribute queries intersected client-side"
+    )
+    parser.add_argument(
+        "--write-ratio",
+        type=float,
+        default=0.0,
+        help="Fraction of operations that are entity writes from a concurrent block writer, e.g. 0.1 (default: 0, read-only)"
+    )
+    parser.add_argument(
+        "--slo",
+        type=str,
+        default=None,
+        help="JSON file with latency/size thresholds; exit code 2 if any is breached"
+    )
+    parser.add_argument(
+        "--rate",
+        type=float,
+        default=None,
+        help="Target queries/sec for the measured phase, paced open-loop (default: unpaced)"
+    )
+    parser.add_argument(
+        "--projection",
+        choices=PROJECTIONS,
+        default=PROJECTION_FULL,
+        help="What point lookups fetch: full entity, attributes without payload, or keys only (default: full)"
+    )
+    parser.add_argument(
+        "--compare-mmap",
+        action="store_true",
+        help="Re-run the same queries with mmap disabled and report the latency delta"
+    )
+    parser.add_argument(
+        "--readers",
+        type=parse_readers,
+        default=None,
+        help="After the main run, run the query mix at each of these concurrent reader counts "
+             "(e.g. 1,2,4,8,16,32,64,128) and report throughput/latency scaling"
+    )
+    parser.add_argument(
+        "--pragma",
+        type=parse_connection_pragma,
+        action="append",
+        default=[],
+        metavar="NAME=VALUE",
+        help=f"Override a SQLite setting on every connection, repeatable ({', '.join(CONNECTION_PRAGMAS)})"
+    )
+    parser.add_argument(
+        "--run-dir",
+        type=str,
+        default=None,
+        help="Write this run's outputs to a new <run-dir>/<db name>_<timestamp>_<pid>/ directory "
+             "(relative --log/--trace paths and the --verify artifact go inside; --log defaults to query_log.csv)"
+    )
     
     args = parser.parse_args()
     
@@ -840,6 +2085,10 @@ def main():
         print(f"Error: Database not found: {args.database}")
         return 1
     
+    # With --run-dir the log defaults to query_log.csv inside the run directory (created below)
+    if args.run_dir:
+        args.log = args.log or "query_log.csv"
+    
     # Parse query mix
     query_mix = QUERY_MIX.copy()
     if args.mix:
@@ -850,6 +2099,85 @@ def main():
             print(f"Error parsing --mix JSON: {e}")
             return 1
     
+    # Load query set
+    query_set: list[NamedQuery] | None = None
+    if args.query_set:
+        try:
+            query_set = load_query_set(args.query_set)
+        except (OSError, ValueError, KeyError) as e:
+            print(f"Error loading --query-set: {e}")
+            return 1
+    elif args.duration is not None:
+        print("Error: --duration requires --query-set")
+        return 1
+    
+    # Parse log sampling rates
+    log_sample: dict[str, float] = {}
+    if args.log_sample or args.log_split:
+        if not args.log:
+            print("Error: --log-sample and --log-split require --log")
+            return 1
+    if args.log_sample:
+        try:
+            log_sample = json.loads(args.log_sample)
+        except json.JSONDecodeError as e:
+            print(f"Error parsing --log-sample JSON: {e}")
+            return 1
+        if not isinstance(log_sample, dict):
+            print("Error: --log-sample must be a JSON object of query type to rate")
+            return 1
+        unknown = set(log_sample) - {qt.value for qt in QueryType}
+        if unknown:
+            print(f"Error: unknown query types in --log-sample: {', '.join(sorted(unknown))}")
+            return 1
+        invalid = [
+            name for name, rate in log_sample.items()
+            if isinstance(rate, bool) or not isinstance(rate, (int, float)) or not 0 <= rate <= 1
+        ]
+        if invalid:
+            print(f"Error: --log-sample rates must be numbers in [0, 1]: {', '.join(sorted(invalid))}")
+            return 1
+    
+    # Load SLO thresholds
+    slo: dict[str, Any] | None = None
+    if args.slo:
+        try:
+            slo = load_slo(args.slo)
+        except (OSError, ValueError) as e:
+            print(f"Error loading --slo: {e}")
+            return 1
+    
+    if args.rate is not None and args.rate <= 0:
+        print("Error: --rate must be positive")
+        return 1
+    
+    if not 0.0 <= args.write_ratio < 1.0:
+        print("Error: --write-ratio must be in [0, 1)")
+        return 1
+    
+    if args.compare_mmap and args.memory < 2:
+        print("Error: --compare-mmap requires --memory >= 2 (mmap is memory - 1 GB)")
+        return 1
+    # Both passes must run the same workload: no concurrent writes (the database would differ
+    # between passes), no tracing or verification (they add work inside or between queries)
+    if args.compare_mmap and (args.write_ratio > 0 or args.trace or args.verify):
+        print("Error: --compare-mmap cannot be combined with --write-ratio, --trace or --verify")
+        return 1
+    
+    # Pick a seed up front so the run is reproducible and its fingerprint unique
+    if args.seed is None:
+        args.seed = random.randint(1, 2**31 - 1)
+    
+    # Per-run directory with the run's arguments, so successive runs never append to the same files
+    run_path = None
+    if args.run_dir:
+        run_path = create_run_dir(args.run_dir, os.path.splitext(os.path.basename(args.database))[0])
+        args.log = os.path.join(run_path, args.log)
+        if args.trace:
+            args.trace = os.path.join(run_path, args.trace)
+        with open(os.path.join(run_path, "run.json"), "w") as f:
+            json.dump(vars(args), f, indent=2)
+    
     # Normalize weights
     total_weight = sum(query_mix.values())
     if total_weight > 0:
@@ -860,35 +2188,136 @@ def main():
     print("=" * 60)
     print(f"Database:           {args.database}")
     print(f"Database size:      {os.path.getsize(args.database) / (1024**3):.2f} GB")
-    print(f"Queries:            {args.queries:,}")
+    if query_set:
+        print(f"Query set:          {args.query_set} ({len(query_set)} queries)")
+        if args.duration is not None:
+            print(f"Duration:           {args.duration:.0f}s")
+        else:
+            print(f"Queries:            {args.queries:,}")
+    else:
+        print(f"Queries:            {args.queries:,}")
     print(f"Warmup:             {args.warmup:,}")
-    print(f"Seed:               {args.seed or 'random'}")
-    print(f"Log file:           {args.log or 'none'}")
+    print(f"Seed:               {args.seed}")
+    if run_path:
+        print(f"Run directory:      {run_path}")
+    print(f"Log file:           {args.log or 'none'}{' (split per query type)' if args.log_split else ''}")
+    if log_sample:
+        print(f"Log sampling:       {', '.join(f'{k}={v:g}' for k, v in log_sample.items())}")
+    print(f"Trace file:         {args.trace or 'none'}")
     print(f"Node limit:         {args.node_limit}")
     print(f"Workload limit:     {args.workload_limit}")
+    print(f"Fan-out:            {'enabled' if args.fanout else 'disabled'}")
+    print(f"Projection:         {args.projection}")
+    print(f"Target rate:        {f'{args.rate:,.0f} queries/sec' if args.rate else 'unpaced'}")
+    print(f"Write ratio:        {args.write_ratio:.0%}")
+    print(f"Compare mmap:       {'enabled' if args.compare_mmap else 'disabled'}")
+    if args.readers:
+        print(f"Reader scaling:     {', '.join(str(n) for n in args.readers)} readers")
     print()
     
+    # Cold start phases are timed individually (ms)
+    cold_start: dict[str, float] = {}
+    phase_start = time.perf_counter()
+    
     # Connect to database
+    pragmas = dict(args.pragma)
     conn = sqlite3.connect(args.database)
-    configure_connection(conn, args.memory)
+    configure_connection(conn, args.memory, pragmas=pragmas)
+    settings = active_pragmas(conn)
+    cold_start["open_ms"] = (time.perf_counter() - phase_start) * 1000
+    
+    # The writer needs WAL so its commits don't block readers; the journal mode is stored
+    # in the database file, so it is checked here rather than changed
+    if args.write_ratio > 0 and settings["journal_mode"] != "wal":
+        print(f"Error: --write-ratio requires a database in WAL mode (journal_mode is "
+              f"{settings['journal_mode']}); convert it with: sqlite3 {args.database} 'PRAGMA journal_mode = WAL'")
+        conn.close()
+        return 1
     
     # Get current block
+    phase_start = time.perf_counter()
     current_block = args.current_block or get_current_block(conn)
+    cold_start["current_block_ms"] = (time.perf_counter() - phase_start) * 1000
     print(f"Current block:      {current_block:,}")
+    print("PRAGMAs:            " + ", ".join(f"{name}={value}" for name, value in settings.items()))
+    
+    fingerprint = workload_fingerprint({
+        "database": os.path.basename(args.database),
+        "current_block": current_block,
+        "seed": args.seed,
+        "queries": args.queries,
+        "warmup": args.warmup,
+        "mix": query_mix,
+        "query_set": [asdict(q) for q in query_set] if query_set else None,
+        "duration": args.duration,
+        "node_limit": args.node_limit,
+        "workload_limit": args.workload_limit,
+        "fanout": args.fanout,
+        # Only when set, so fingerprints of runs with the defaults stay unchanged
+        **({"write_ratio": args.write_ratio} if args.write_ratio > 0 else {}),
+        **({"rate": args.rate} if args.rate else {}),
+        **({"projection": args.projection} if args.projection != PROJECTION_FULL else {}),
+        **({"pragmas": pragmas} if pragmas else {}),
+    })
+    print(f"Fingerprint:        {fingerprint}")
     print()
     
+    # Record the starting dataset state with the log, so every logged run is self-describing
+    # (the summary scans the attribute tables, so it is skipped without --log)
+    dataset: dict[str, Any] = {}
+    if args.log:
+        phase_start = time.perf_counter()
+        dataset = summarize_dataset(conn, current_block)
+        cold_start["dataset_summary_ms"] = (time.perf_counter() - phase_start) * 1000
+        print_dataset_summary(dataset)
+        dataset_path = f"{args.log}.dataset.json"
+        with open(dataset_path, "w") as f:
+            json.dump({"database": args.database, "fingerprint": fingerprint, "pragmas": settings, **dataset},
+                      f, indent=2)
+        print(f"Dataset summary written to: {dataset_path}")
+        print()
+    
     # Open log file if specified
-    log_file = open(args.log, "w", newline="") if args.log else None
+    log_file = open(args.log, "w", newline="") if args.log and not args.log_split else None
+    
+    # Open trace file if specified
+    trace_file = open(args.trace, "w") if args.trace else None
+    tracer = CallTracer(trace_file) if trace_file else None
+    
+    # Open one extra connection per node filter predicate for fan-out queries
+    fanout_conns: list[sqlite3.Connection] = []
+    if args.fanout:
+        for _ in range(FANOUT_PREDICATES):
+            fanout_conn = sqlite3.connect(args.database, check_same_thread=False)
+            configure_connection(fanout_conn, args.memory, verbose=False, pragmas=pragmas)
+            fanout_conns.append(fanout_conn)
     
     # Initialize components
     print("Initializing...")
+    phase_start = time.perf_counter()
     generator = QueryGenerator(conn, current_block, args.seed)
+    cold_start["sample_load_ms"] = (time.perf_counter() - phase_start) * 1000
     executor = QueryExecutor(
         conn, current_block, log_file,
         node_limit=args.node_limit,
         workload_limit=args.workload_limit,
+        fanout_conns=fanout_conns,
+        tracer=tracer,
+        fingerprint=fingerprint,
+        projection=args.projection,
+        log_sample=log_sample,
+        split_log_path=args.log if args.log_split else None,
+    )
+    verifier = None
+    if args.verify > 0:
+        verify_path = os.path.join(run_path, "verify.jsonl") if run_path else f"{args.database}.verify.jsonl"
+        verifier = ResultVerifier(conn, args.verify, verify_path)
+    runner = BenchmarkRunner(
+        conn, generator, executor, query_mix,
+        fanout=args.fanout,
+        verifier=verifier,
+        rate=args.rate,
     )
-    runner = BenchmarkRunner(conn, generator, executor, query_mix)
     
     # Check if we have enough sample data
     if not generator._node_ids and not generator._workload_ids:
@@ -898,13 +2327,62 @@ def main():
     
     print()
     
+    # Start the concurrent block writer (WAL lets readers proceed during commits)
+    writer = None
+    if args.write_ratio > 0:
+        writer = BlockWriter(
+            args.database,
+            start_block=get_current_block(conn) + 1,
+            write_ratio=args.write_ratio,
+            reads=lambda: executor.query_count,
+            seed=args.seed,
+            pragmas=pragmas,
+        )
+        writer.start()
+    
+    # With --compare-mmap the mmap-off pass runs before or after the main (mmap) pass in an
+    # order drawn from the seed, with the database evicted from the page cache before each
+    # pass, so neither pass systematically runs on a cache warmed by the other
+    no_mmap_first = args.compare_mmap and random.Random(f"{args.seed}:mmap-order").random() < 0.5
+    no_mmap_stats = None
+    
+    def compare_pass() -> dict[str, Any]:
+        drop_page_cache(args.database)
+        return run_no_mmap_pass(
+            args.database, args.memory, current_block, query_mix, args.seed,
+            query_set, args.duration, args.queries, args.warmup,
+            fanout=args.fanout,
+            rate=args.rate,
+            executor_options={
+                "node_limit": args.node_limit,
+                "workload_limit": args.workload_limit,
+                "projection": args.projection,
+            },
+            pragmas=pragmas,
+        )
+    
+    if no_mmap_first:
+        print("Running the mmap-off pass first...")
+        no_mmap_stats = compare_pass()
+        print()
+    if args.compare_mmap:
+        drop_page_cache(args.database)
+    
     # Run benchmark
     start_time = time.time()
-    results = runner.run(args.queries, args.warmup)
+    if query_set:
+        results = runner.run_query_set(query_set, args.duration, args.queries, args.warmup)
+    else:
+        results = runner.run(args.queries, args.warmup)
     total_time = time.time() - start_time
+    if writer:
+        writer.stop()
     
-    # Compute statistics
-    stats = BenchmarkRunner.compute_statistics(results)
+    # Compute statistics (fan-out re-runs separately, so they don't skew the mix totals)
+    stats = BenchmarkRunner.compute_statistics(results, runner.phase_seconds)
+    if runner.fanout_results:
+        fanout_stats = BenchmarkRunner.compute_statistics(runner.fanout_results)
+        stats["fanout"] = fanout_stats["by_type"].get(QueryType.NODE_FILTER_FANOUT.value)
     
     # Print report
     config = {
@@ -914,6 +2392,126 @@ def main():
     }
     Reporter.print_report(stats, config)
     
+    first = executor.first_result
+    if first:
+        cold_start["first_query_ms"] = first.latency_ms
+    print()
+    print("--- Cold Start (ms) ---")
+    print(f"Open + configure:   {cold_start['open_ms']:.2f}")
+    print(f"Current block:      {cold_start['current_block_ms']:.2f}")
+    if "dataset_summary_ms" in cold_start:
+        print(f"Dataset summary:    {cold_start['dataset_summary_ms']:.2f}")
+    print(f"Sample load:        {cold_start['sample_load_ms']:.2f}")
+    if first:
+        print(f"First query:        {first.latency_ms:.2f} ({first.query_type.value})")
+    if args.log:
+        cold_start_path = f"{args.log}.coldstart.json"
+        with open(cold_start_path, "w") as f:
+            json.dump({
+                "database": args.database,
+                "fingerprint": fingerprint,
+                "entities": dataset.get("live_entities"),
+                **cold_start,
+            }, f, indent=2)
+        print(f"Cold start timings written to: {cold_start_path}")
+    
+    if writer:
+        commits = sorted(writer.commit_latencies_ms)
+        print()
+        print("--- Concurrent Writes ---")
+        print(f"Blocks written:     {writer.blocks_written:,} "
+              f"(blocks {writer.start_block:,} - {writer.final_block:,})")
+        print(f"Entities written:   {writer.entities_written:,}")
+        total_ops = writer.entities_written + executor.query_count
+        print(f"Write share:        {writer.entities_written / total_ops:.1%} of operations")
+        if commits:
+            print(f"Block write p50:    {commits[len(commits) // 2]:.2f}ms")
+            print(f"Block write max:    {commits[-1]:.2f}ms")
+        if writer.error:
+            print(f"Writer error:       {writer.error}")
+    
+    if args.rate:
+        achieved = len(results) / runner.phase_seconds if runner.phase_seconds > 0 else 0
+        print()
+        print("--- Pacing ---")
+        print(f"Target rate:        {args.rate:,.1f} queries/sec")
+        print(f"Achieved rate:      {achieved:,.1f} queries/sec")
+        print(f"Late queries:       {runner.late_queries:,} (started >1ms behind schedule)")
+    
+    if verifier:
+        print()
+        print("--- Result Verification (NOT INDEXED oracle) ---")
+        print(f"Checked:            {verifier.checked:,}")
+        print(f"Mismatches:         {verifier.mismatches:,}")
+        if verifier.mismatches:
+            print(f"Artifacts:          {verifier.artifact_path}")
+    
+    if args.compare_mmap:
+        if no_mmap_stats is None:
+            print()
+            print("Re-running with mmap disabled...")
+            no_mmap_stats = compare_pass()
+        Reporter.print_mmap_comparison(stats, no_mmap_stats, no_mmap_first)
+    
+    if args.readers:
+        print()
+        scaling = []
+        for readers in args.readers:
+            print(f"Running {args.queries:,} queries on each of {readers} concurrent readers...")
+            try:
+                level = run_reader_level(
+                    args.database, args.memory, current_block, query_mix,
+                    readers=readers,
+                    queries_per_reader=args.queries,
+                    warmup=args.warmup,
+                    seed=args.seed,
+                    executor_options={
+                        "node_limit": args.node_limit,
+                        "workload_limit": args.workload_limit,
+                        "projection": args.projection,
+                    },
+                    write_ratio=args.write_ratio,
+                    pragmas=pragmas,
+                )
+            except RuntimeError as e:
+                print(f"Error: {e}")
+                return 1
+            scaling.append(level)
+        Reporter.print_reader_scaling(scaling)
+    
+    slo_breached = False
+    if slo:
+        measured: dict[str, Any] = {**stats["by_type"]}
+        if stats.get("fanout"):
+            measured[QueryType.NODE_FILTER_FANOUT.value] = stats["fanout"]
+        if "overall" in stats:
+            measured["overall"] = stats["overall"]
+        if writer and writer.commit_latencies_ms:
+            commits = sorted(writer.commit_latencies_ms)
+            n = len(commits)
+            measured["write_block"] = {
+                "p50": commits[int(n * 0.50)],
+                "p95": commits[int(n * 0.95)],
+                "p99": commits[int(n * 0.99)],
+                "max": commits[-1],
+                "avg": sum(commits) / n,
+            }
+        live_entities = dataset.get("live_entities")
+        if live_entities is None:
+            live_entities = count_live_entities(conn, current_block)
+        entities = live_entities + (writer.entities_written if writer else 0)
+        if entities:
+            measured["bytes_per_entity"] = database_size(args.database) / entities
+        
+        print()
+        print(f"--- SLO ({args.slo}) ---")
+        print(f"{'Metric':<28} {'Limit':>12} {'Measured':>12}  Result")
+        for metric, limit, value, ok in check_slo(slo, measured):
+            shown = f"{value:>12.2f}" if value is not None else f"{'no data':>12}"
+            print(f"{metric:<28} {limit:>12.2f} {shown}  {'PASS' if ok else 'FAIL'}")
+            slo_breached = slo_breached or not ok
+        print(f"SLO result:         {'BREACHED' if slo_breached else 'met'}")
+    
     print(f"\nTotal benchmark time: {total_time:.1f}s")
     
     # Cleanup
@@ -921,9 +2519,28 @@ def main():
         log_file.close()
         print(f"Query log written to: {args.log}")
     
+    if executor.split_log_files:
+        executor.close_logs()
+        print("Query logs written to:")
+        for query_type, split_file in executor.split_log_files.items():
+            print(f"  {split_file.name} ({executor.logged_counts.get(query_type, 0):,} queries)")
+    
+    if log_sample:
+        print("Logged queries (sampled):")
+        for query_type, count in executor.logged_counts.items():
+            print(f"  {query_type.value:<20} {count:>8,}")
+    
+    if trace_file:
+        trace_file.close()
+        print(f"Call trace written to: {args.trace}")
+    
+    if executor.fanout_pool:
+        executor.fanout_pool.shutdown()
+    for fanout_conn in fanout_conns:
+        fanout_conn.close()
     conn.close()
-    return 0
+    return 2 if slo_breached else 0
 
 
 if __name__ == "__main__":
-    exit(main())
+    sys.exit(main())
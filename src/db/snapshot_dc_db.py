@@ -0,0 +1,112 @@
+"""
+Snapshot and restore a Data Center database while it may be in use.
+
+snapshot copies a consistent state of the database with the SQLite online backup
+API (or VACUUM INTO, which also compacts it) while other connections read or write,
+so long benchmark runs can be checkpointed and replayed from a known state. restore
+copies a snapshot back over a target database the same way; it takes the target's
+write lock, so a writer on the target is blocked (or fails with "database is locked")
+until it completes and afterwards sees the restored content.
+
+Usage:
+    uv run python -m src.db.snapshot_dc_db snapshot data/dc_blocks.db data/dc_blocks_b1000.db
+    uv run python -m src.db.snapshot_dc_db snapshot data/dc_blocks.db data/dc_blocks_b1000.db --vacuum
+    uv run python -m src.db.snapshot_dc_db restore data/dc_blocks_b1000.db data/dc_blocks.db
+"""
+
+import argparse
+import os
+import sqlite3
+import sys
+import time
+
+from .query_dc_benchmark import get_current_block
+
+
+def backup(source_path: str, target_path: str) -> None:
+    """
+    Copy source to target with the online backup API.
+    
+    The copy runs in one step (one read transaction on the source): a stepped copy
+    restarts whenever another connection writes the source, so it may never finish
+    under a steady writer.
+    """
+    source = sqlite3.connect(source_path)
+    target = sqlite3.connect(target_path)
+    try:
+        source.backup(target, pages=-1)
+    finally:
+        target.close()
+        source.close()
+
+
+def vacuum_into(source_path: str, target_path: str) -> None:
+    """Copy source to target with VACUUM INTO (compacted, target must not exist)."""
+    source = sqlite3.connect(source_path)
+    try:
+        source.execute("VACUUM INTO ?", (target_path,))
+    finally:
+        source.close()
+
+
+def describe(path: str) -> str:
+    """Current block and file size of a database, for the report."""
+    conn = sqlite3.connect(path)
+    try:
+        block = get_current_block(conn)
+    finally:
+        conn.close()
+    return f"block {block:,}, {os.path.getsize(path) / (1024**2):.1f} MB"
+
+
+def main():
+    parser = argparse.ArgumentParser(
+        description="Snapshot and restore a Data Center database while it may be in use"
+    )
+    subparsers = parser.add_subparsers(dest="command", required=True)
+
+    snapshot_parser = subparsers.add_parser("snapshot", help="Copy a database to a snapshot file")
+    snapshot_parser.add_argument("database", type=str, help="Database to snapshot")
+    snapshot_parser.add_argument("snapshot", type=str, help="Snapshot file to create")
+    snapshot_parser.add_argument(
+        "--vacuum",
+        action="store_true",
+        help="Use VACUUM INTO instead of the backup API (compacts the snapshot)"
+    )
+
+    restore_parser = subparsers.add_parser("restore", help="Copy a snapshot back over a database")
+    restore_parser.add_argument("snapshot", type=str, help="Snapshot file to restore")
+    restore_parser.add_argument("database", type=str, help="Database to overwrite")
+
+    args = parser.parse_args()
+
+    source, target = (
+        (args.database, args.snapshot) if args.command == "snapshot" else (args.snapshot, args.database)
+    )
+    if not os.path.exists(source):
+        print(f"Error: Database not found: {source}", file=sys.stderr)
+        return 1
+    if args.command == "snapshot" and os.path.exists(target):
+        print(f"Error: Snapshot already exists: {target}", file=sys.stderr)
+        return 1
+
+    print(f"{args.command.capitalize()}: {source} -> {target}")
+    start = time.perf_counter()
+    try:
+        if args.command == "snapshot" and args.vacuum:
+            vacuum_into(source, target)
+        else:
+            backup(source, target)
+    except sqlite3.Error as e:
+        print(f"Database error: {e}", file=sys.stderr)
+        return 1
+    elapsed = time.perf_counter() - start
+
+    print(f"Source:             {describe(source)}")
+    print(f"Target:             {describe(target)}")
+    print(f"Time:               {elapsed:.2f}s")
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,119 @@
+"""Tests for the analyze_block_csv module."""
+
+import random
+
+import pytest
+
+from db.analyze_block_csv import FEATURES, analyze, solve
+
+
+def make_rows(count, write_time, payload_kb=None):
+    """Block CSV rows with random features and write time computed from them."""
+    rng = random.Random(3)
+    rows = []
+    for block in range(count):
+        features = {
+            "num_entities": rng.randint(5, 50),
+            "num_updates": rng.randint(0, 10),
+            "num_deletes": 0,
+            "payload_kb": rng.randint(10, 500),
+            "db_size_kb": 1000 + block * 10,
+        }
+        if payload_kb:
+            features["payload_kb"] = payload_kb(features)
+        # Attribute counts scale with num_entities, as in real block CSVs
+        features["num_string_attrs"] = features["num_entities"] * 9
+        features["num_numeric_attrs"] = features["num_entities"] * 8
+        rows.append({
+            **{name: str(value) for name, value in features.items()},
+            "write_time_ms": str(write_time(features, rng)),
+            "commit_time_ms": "1.0",
+        })
+    return rows
+
+
+class TestAnalyze:
+    """Tests for analyze function."""
+
+    def test_finds_driving_feature(self):
+        """Should rank the feature the latency depends on first."""
+        rows = make_rows(200, lambda f, rng: 0.02 * f["payload_kb"] + rng.gauss(0, 0.1))
+
+        result = analyze(rows, "write")
+
+        assert result["blocks"] == 200
+        assert next(iter(result["single"])) == "payload_kb"
+        assert result["single"]["payload_kb"]["r2"] > 0.9
+        assert result["single"]["payload_kb"]["slope"] == pytest.approx(0.02, rel=0.05)
+
+    def test_constant_and_collinear_features(self):
+        """Should report constant features and leave collinear ones out of the multiple fit."""
+        rows = make_rows(100, lambda f, rng: 0.1 * f["num_entities"] + rng.gauss(0, 0.1))
+
+        result = analyze(rows, "write")
+
+        assert result["constant"] == ["num_deletes"]
+        # Of the three features scaling with num_entities one is kept, the others are collinear
+        scaled = {"num_entities", "num_string_attrs", "num_numeric_attrs"}
+        assert len(scaled & set(result["collinear"])) == 2
+        assert set(result["multiple"]["std_coef"]).isdisjoint(result["collinear"])
+        assert set(result["single"]) == set(FEATURES) - {"num_deletes"}
+
+    def test_multiple_fit_combines_features(self):
+        """Should explain a latency driven by two features better together than alone."""
+        rows = make_rows(
+            300, lambda f, rng: 0.02 * f["payload_kb"] + 0.5 * f["num_updates"] + rng.gauss(0, 0.1)
+        )
+
+        result = analyze(rows, "write")
+
+        assert result["multiple"]["r2"] > 0.95
+        assert result["multiple"]["r2"] > max(fit["r2"] for fit in result["single"].values())
+
+    def test_apply_target_sums_columns(self):
+        """Should sum write and commit time for the apply target."""
+        rows = make_rows(50, lambda f, rng: 2.0 + rng.random())
+
+        write = analyze(rows, "write")
+        apply = analyze(rows, "apply")
+
+        assert apply["target_avg_ms"] == pytest.approx(write["target_avg_ms"] + 1.0)
+
+    def test_best_factor_by_r2_loss(self):
+        """Should pick the feature whose removal loses the most R² as the best factor."""
+        rows = make_rows(
+            300, lambda f, rng: 0.02 * f["payload_kb"] + 0.1 * f["num_updates"] + rng.gauss(0, 0.1)
+        )
+
+        result = analyze(rows, "write")
+
+        assert result["best"] == "payload_kb"
+        loss = result["multiple"]["r2_loss"]
+        assert loss["payload_kb"] > loss["num_updates"] > loss["db_size_kb"]
+
+    def test_linearly_dependent_features_left_out(self):
+        """Should leave out features that make the normal equations singular."""
+        rows = make_rows(
+            100,
+            lambda f, rng: 0.01 * f["payload_kb"] + rng.gauss(0, 0.1),
+            payload_kb=lambda f: 10 * f["num_entities"] + 10 * f["num_updates"] + f["db_size_kb"],
+        )
+
+        result = analyze(rows, "write")
+
+        assert len(result["dependent"]) == 1
+        assert result["dependent"][0] not in result["multiple"]["std_coef"]
+        assert all(abs(coef) < 10 for coef in result["multiple"]["std_coef"].values())
+
+
+class TestSolve:
+    """Tests for solve function."""
+
+    def test_solves_system(self):
+        """Should solve a well-conditioned system."""
+        assert solve([[2.0, 1.0], [1.0, 3.0]], [3.0, 5.0]) == pytest.approx([0.8, 1.4])
+
+    def test_singular_raises(self):
+        """Should reject a singular system instead of dividing by a zero pivot."""
+        with pytest.raises(ValueError, match="singular"):
+            solve([[1.0, 2.0], [2.0, 4.0]], [1.0, 2.0])
@@ -0,0 +1,364 @@
+"""Tests for the append_dc_data module."""
+
+import csv
+import gzip
+import io
+import json
+import lzma
+import random
+import zlib
+
+import pytest
+
+from db.append_dc_data import (
+    BLOCK_CSV_HEADER,
+    COMPRESSION_CODECS,
+    INSERT_TABLES,
+    PAYLOAD_PROFILES,
+    append_blocks,
+    audit_block,
+    compress_payload,
+    generate_blocks,
+    init_database,
+    make_payload,
+    node_to_sql_inserts,
+    payload_content_type,
+    query_expired_entities,
+    workload_to_sql_inserts,
+    zstd,
+)
+
+# Small block shape shared by the generator and append tests
+BLOCK_SHAPE = {
+    "num_blocks": 6,
+    "nodes_per_block": 3,
+    "workloads_per_node": 4,
+    "percentage_assigned": 0.5,
+    "payload_size": 32,
+    "start_block": 1,
+    "seed": 11,
+}
+
+
+def append(tmp_path, **options):
+    """Append BLOCK_SHAPE blocks (options override) to a new database, return the connection and result."""
+    db_path = str(tmp_path / "dc.db")
+    conn = init_database(db_path)
+    result = append_blocks(conn=conn, db_path=db_path, **{**BLOCK_SHAPE, **options})
+    return conn, result
+
+
+def ordering_attrs(conn, block):
+    """entity_key -> (tx, op, sequence) stored for the versions written in a block."""
+    attrs = {}
+    for entity_key, key, value in conn.execute("""
+        SELECT entity_key, key, value FROM numeric_attributes
+        WHERE from_block = ? AND key IN ('$txIndex', '$opIndex', '$sequence')
+    """, (block,)):
+        attrs.setdefault(entity_key, {})[key] = value
+    return {key: (a["$txIndex"], a["$opIndex"], a["$sequence"]) for key, a in attrs.items()}
+
+
+class TestMakePayload:
+    """Tests for make_payload function."""
+
+    @pytest.mark.parametrize("profile", PAYLOAD_PROFILES)
+    def test_exact_size(self, profile):
+        """Should return exactly payload_size bytes for every profile."""
+        for size in [0, 1, 63, 64, 1000]:
+            assert len(make_payload(random.Random(1), size, profile)) == size
+
+    @pytest.mark.parametrize("profile", PAYLOAD_PROFILES)
+    def test_deterministic(self, profile):
+        """Should return the same payload for the same seed."""
+        assert make_payload(random.Random(5), 500, profile) == make_payload(random.Random(5), 500, profile)
+
+    def test_zero_profile(self):
+        """Should fill the zero profile with zero bytes."""
+        assert make_payload(random.Random(1), 100, "zero") == bytes(100)
+
+    def test_repeat_profile(self):
+        """Should repeat one 64-byte pattern."""
+        payload = make_payload(random.Random(1), 200, "repeat")
+        assert payload[:64] == payload[64:128] == payload[128:192]
+
+    def test_json_profile_is_text(self):
+        """Should produce JSON-like ASCII text."""
+        payload = make_payload(random.Random(1), 300, "json")
+        assert payload.startswith(b"[{")
+        assert payload.decode("ascii")
+
+
+class TestCompressPayload:
+    """Tests for compress_payload function."""
+
+    DECOMPRESS = {
+        "none": lambda data: data,
+        "gzip": gzip.decompress,
+        "zlib": zlib.decompress,
+        "lzma": lzma.decompress,
+        **({"zstd": zstd.decompress} if zstd else {}),
+    }
+
+    @pytest.mark.parametrize("codec", COMPRESSION_CODECS)
+    def test_round_trip(self, codec):
+        """Should compress so that the codec's decompressor restores the payload."""
+        payload = make_payload(random.Random(1), 2000, "json")
+        assert self.DECOMPRESS[codec](compress_payload(payload, codec)) == payload
+
+    def test_none_is_unchanged(self):
+        """Should return the payload itself for codec none."""
+        payload = b"abc"
+        assert compress_payload(payload, "none") is payload
+
+    def test_compressible_payload_shrinks(self):
+        """Should shrink a compressible payload."""
+        payload = make_payload(random.Random(1), 4000, "repeat")
+        for codec in COMPRESSION_CODECS[1:]:
+            assert len(compress_payload(payload, codec)) < len(payload)
+
+    def test_gzip_is_deterministic(self):
+        """Should not embed a timestamp in gzip output."""
+        payload = make_payload(random.Random(1), 500, "json")
+        assert compress_payload(payload, "gzip") == compress_payload(payload, "gzip")
+
+    def test_content_type_records_codec(self):
+        """Should append the codec to the content type of compressed payloads."""
+        assert payload_content_type("none") == "application/octet-stream"
+        assert payload_content_type("gzip") == "application/octet-stream+gzip"
+
+
+class TestOrderingAudit:
+    """Tests for the stored ordering attributes and audit_block."""
+
+    def test_stored_ordering_columns(self, tmp_path):
+        """Should store one transaction per node and its workloads, and a block-wide sequence."""
+        conn, _ = append(tmp_path)
+        block = next(generate_blocks(**BLOCK_SHAPE))
+
+        stored = ordering_attrs(conn, 1)
+
+        per_node = BLOCK_SHAPE["workloads_per_node"]
+        for i, node in enumerate(block.nodes):
+            assert stored[node.entity_key] == (i, 0, i * (per_node + 1))
+            for j, workload in enumerate(block.workloads[i * per_node:(i + 1) * per_node]):
+                assert stored[workload.entity_key] == (i, j + 1, i * (per_node + 1) + j + 1)
+        assert sorted(seq for _, _, seq in stored.values()) == list(range(len(stored)))
+        conn.close()
+
+    def test_audit_passes_with_churn_and_batches(self, tmp_path):
+        """Should find no mismatches when reading back batched blocks with updates and deletes."""
+        conn, result = append(
+            tmp_path, audit=True, batch_size=4,
+            same_block_updates=0.3, same_block_deletes=0.2, update_ratio=0.3,
+        )
+        assert result[3] == 0
+        conn.close()
+
+    def test_audit_detects_wrong_sequence(self, tmp_path):
+        """Should report entities whose stored $sequence differs from the operation order."""
+        conn, _ = append(tmp_path)
+        conn.execute("UPDATE numeric_attributes SET value = value + 100 WHERE key = '$sequence' AND from_block = 2")
+        conn.commit()
+        block = list(generate_blocks(**BLOCK_SHAPE))[1]
+
+        mismatches = audit_block(conn, block)
+
+        assert len(mismatches) == len(block.nodes) + len(block.workloads)
+        assert all("expected tx/op/seq" in mismatch for mismatch in mismatches)
+        conn.close()
+
+
+class TestSameBlockChurn:
+    """Tests for same-block updates and deletes of new workloads."""
+
+    CHURN = {"same_block_updates": 0.4, "same_block_deletes": 0.3}
+
+    def test_churn_follows_creates(self):
+        """Should put updates and deletes in one transaction after all creates."""
+        for block in generate_blocks(**BLOCK_SHAPE, **self.CHURN):
+            churn = sorted([*block.updates, *block.deletes], key=lambda wl: wl.sequence)
+            creates = [*block.nodes, *block.workloads]
+            assert churn
+            assert {wl.tx_index for wl in churn} == {max(e.tx_index for e in creates) + 1}
+            assert [wl.op_index for wl in churn] == list(range(len(churn)))
+            assert churn[0].sequence == len(creates)
+
+    def test_update_leaves_one_version(self, tmp_path):
+        """Should replace the created version instead of adding a second one for the same key."""
+        conn, _ = append(tmp_path, **self.CHURN)
+        blocks = list(generate_blocks(**BLOCK_SHAPE, **self.CHURN))
+
+        for block in blocks:
+            for workload in block.updates:
+                rows = conn.execute(
+                    "SELECT from_block, value FROM string_attributes WHERE entity_key = ? AND key = 'status'",
+                    (workload.entity_key,),
+                ).fetchall()
+                assert rows == [(block.block_num, "completed")]
+        conn.close()
+
+    def test_delete_hides_entity(self, tmp_path):
+        """Should leave no version of a workload deleted in its creation block visible."""
+        conn, _ = append(tmp_path, **self.CHURN)
+        blocks = list(generate_blocks(**BLOCK_SHAPE, **self.CHURN))
+
+        for block in blocks:
+            for workload in block.deletes:
+                visible = conn.execute(
+                    "SELECT COUNT(*) FROM payloads WHERE entity_key = ? AND to_block > ?",
+                    (workload.entity_key, block.block_num),
+                ).fetchone()[0]
+                assert visible == 0
+        conn.close()
+
+
+class TestLifecycleLog:
+    """Tests for the --lifecycle-log events written by append_blocks."""
+
+    def test_replay_matches_live_set(self, tmp_path):
+        """Should replay to exactly the entities live in the database after the last block."""
+        log = io.StringIO()
+        conn, (_, _, final_block, _) = append(
+            tmp_path, lifecycle_log=log, ttl_blocks=3, update_ratio=0.3,
+            same_block_updates=0.2, same_block_deletes=0.2,
+        )
+        events = [json.loads(line) for line in log.getvalue().splitlines()]
+
+        live = set()
+        for event in events:
+            if event["op"] in ("create", "update"):
+                live.add(event["entity_key"])
+            elif event["op"] in ("delete", "expire"):
+                live.discard(event["entity_key"])
+
+        stored = {
+            row[0].hex() for row in conn.execute(
+                "SELECT DISTINCT entity_key FROM payloads WHERE to_block > ?", (final_block,)
+            )
+        }
+        assert any(event["op"] == "expire" for event in events)
+        assert live == stored
+        conn.close()
+
+    def test_expire_events_come_first(self, tmp_path):
+        """Should log a block's expire events before its operations, without a sequence."""
+        log = io.StringIO()
+        conn, _ = append(tmp_path, lifecycle_log=log, ttl_blocks=2)
+        events = [json.loads(line) for line in log.getvalue().splitlines()]
+
+        for block in range(1, BLOCK_SHAPE["num_blocks"] + 1):
+            ops = [event for event in events if event["block"] == block and event["op"] != "start"]
+            expired = [event for event in ops if event["op"] == "expire"]
+            assert ops[:len(expired)] == expired
+            assert all("sequence" not in event for event in expired)
+            # Everything created two blocks earlier expires
+            per_block = BLOCK_SHAPE["nodes_per_block"] * (1 + BLOCK_SHAPE["workloads_per_node"])
+            assert len(expired) == (per_block if block > 2 else 0)
+        conn.close()
+
+
+class TestVersionUpdates:
+    """Tests for updates of workloads created in earlier blocks (update_ratio)."""
+
+    def test_versions_keep_created_block_and_expiration(self, tmp_path):
+        """Should chain versions and keep $createdAtBlock and $expiration of the first one."""
+        conn, _ = append(tmp_path, update_ratio=0.5, ttl_blocks=50)
+        updated = {
+            wl.entity_key
+            for block in generate_blocks(**BLOCK_SHAPE, update_ratio=0.5, ttl_blocks=50)
+            for wl in block.version_updates
+        }
+
+        assert updated
+        for entity_key in updated:
+            versions = conn.execute("""
+                SELECT from_block, to_block,
+                       MAX(CASE WHEN key = '$createdAtBlock' THEN value END),
+                       MAX(CASE WHEN key = '$expiration' THEN value END)
+                FROM numeric_attributes WHERE entity_key = ?
+                GROUP BY from_block, to_block ORDER BY from_block
+            """, (entity_key,)).fetchall()
+            first_block, _, created, expiration = versions[0]
+            assert len(versions) > 1
+            assert created == first_block
+            for (_, to_block, _, _), (from_block, _, _, _) in zip(versions, versions[1:]):
+                assert to_block == from_block
+            assert {(v[2], v[3]) for v in versions} == {(created, expiration)}
+        conn.close()
+
+
+class TestTransactionShaping:
+    """Tests for --ops-per-tx transaction shaping."""
+
+    def test_ops_per_tx_regroups_creates(self):
+        """Should split the creates in sequence order into transactions of the sampled size."""
+        for block in generate_blocks(**BLOCK_SHAPE, ops_per_tx=(2, 3)):
+            creates = sorted([*block.nodes, *block.workloads], key=lambda entity: entity.sequence)
+            sizes = {}
+            for entity in creates:
+                assert entity.op_index == sizes.get(entity.tx_index, 0)
+                sizes[entity.tx_index] = entity.op_index + 1
+            assert [entity.tx_index for entity in creates] == sorted(entity.tx_index for entity in creates)
+            assert list(sizes) == list(range(len(sizes)))
+            assert all(2 <= size <= 3 for size in list(sizes.values())[:-1])
+            assert 1 <= sizes[len(sizes) - 1] <= 3
+
+    def test_version_updates_take_next_unused_tx(self):
+        """Should put version updates in the transaction after the creates and churn."""
+        for churn in [0.0, 0.4]:
+            blocks = generate_blocks(
+                **BLOCK_SHAPE, ops_per_tx=(2, 5), update_ratio=0.5, same_block_updates=churn,
+            )
+            for block in blocks:
+                if not block.version_updates:
+                    continue
+                previous = [*block.nodes, *block.workloads, *block.updates]
+                assert {wl.tx_index for wl in block.version_updates} == {
+                    max(entity.tx_index for entity in previous) + 1
+                }
+
+    def test_audit_passes_with_ops_per_tx(self, tmp_path):
+        """Should store the regrouped transaction indices the audit expects."""
+        conn, result = append(tmp_path, audit=True, ops_per_tx=(1, 4), update_ratio=0.3, same_block_updates=0.3)
+        assert result[3] == 0
+        conn.close()
+
+
+class TestBlockCsv:
+    """Tests for the --block-csv records written by append_blocks."""
+
+    def test_counts_include_updates(self, tmp_path):
+        """Should count the attribute rows and payloads of updates, not only of creates."""
+        churn = {"same_block_updates": 0.4, "update_ratio": 0.3, "payload_size": 1000}
+        block_csv = io.StringIO()
+        conn, _ = append(tmp_path, block_csv=block_csv, **churn)
+        records = [dict(zip(BLOCK_CSV_HEADER, row)) for row in csv.reader(io.StringIO(block_csv.getvalue()))]
+
+        for record, block in zip(records, generate_blocks(**{**BLOCK_SHAPE, **churn}), strict=True):
+            workloads = [*block.workloads, *block.updates, *block.version_updates]
+            inserts = [sql for node in block.nodes for sql, _ in node_to_sql_inserts(node, "0x00")]
+            inserts += [sql for wl in workloads for sql, _ in workload_to_sql_inserts(wl, "0x00")]
+            tables = [INSERT_TABLES[sql] for sql in inserts]
+            assert int(record["num_string_attrs"]) == tables.count("string_attributes")
+            assert int(record["num_numeric_attrs"]) == tables.count("numeric_attributes")
+            payload_bytes = sum(len(entity.payload) for entity in [*block.nodes, *workloads])
+            assert int(record["payload_kb"]) == payload_bytes // 1024
+        conn.close()
+
+
+class TestExpiredEntities:
+    """Tests for query_expired_entities function."""
+
+    def test_deleted_entities_are_not_expired(self, tmp_path):
+        """Should return the entities created ttl blocks earlier, except those deleted since."""
+        options = {"ttl_blocks": 2, "same_block_deletes": 0.5}
+        conn, _ = append(tmp_path, **options)
+        first = next(generate_blocks(**{**BLOCK_SHAPE, **options}))
+        deleted = {wl.entity_key for wl in first.deletes}
+
+        expired = set(query_expired_entities(conn.cursor(), 3))
+
+        assert deleted
+        assert expired == {entity.entity_key for entity in [*first.nodes, *first.workloads]} - deleted
+        conn.close()
@@ -0,0 +1,240 @@
+"""Tests for the query_dc_benchmark module."""
+
+import json
+from dataclasses import replace
+
+import pytest
+
+from db.append_dc_data import (
+    INDEX_SQL,
+    generate_blocks,
+    init_database,
+    node_to_sql_inserts,
+    parse_pragma,
+)
+from db.query_dc_benchmark import (
+    REGIONS,
+    VM_TYPES,
+    QueryParams,
+    QueryType,
+    ResultVerifier,
+    check_slo,
+    load_query_set,
+    load_slo,
+    parse_connection_pragma,
+    parse_duration,
+)
+
+
+def write_json(path, data):
+    """Write data as JSON and return the path as a string."""
+    path.write_text(json.dumps(data))
+    return str(path)
+
+
+class TestParseDuration:
+    """Tests for parse_duration function."""
+
+    def test_plain_number_is_seconds(self):
+        """Should read a number without unit as seconds."""
+        assert parse_duration("90") == 90
+
+    def test_units(self):
+        """Should convert s, m and h suffixes to seconds."""
+        assert parse_duration("30s") == 30
+        assert parse_duration("5m") == 300
+        assert parse_duration("1h") == 3600
+        assert parse_duration("1.5m") == 90
+
+    def test_invalid_raises(self):
+        """Should reject values that are not durations."""
+        with pytest.raises(ValueError):
+            parse_duration("5x")
+
+
+class TestLoadQuerySet:
+    """Tests for load_query_set function."""
+
+    def test_loads_queries(self, tmp_path):
+        """Should load names, types and params, decoding hex entity keys."""
+        path = write_json(tmp_path / "set.json", {"queries": [
+            {"name": "cheap", "type": "node_filter", "params": {"region": "eu-west", "max_price": 200}},
+            {"name": "key", "type": "point_by_key", "params": {"entity_key": "0x00ff"}},
+            {"name": "pending", "type": "workload_simple"},
+        ]})
+
+        queries = load_query_set(path)
+
+        assert [q.name for q in queries] == ["cheap", "key", "pending"]
+        assert queries[0].query_type == QueryType.NODE_FILTER
+        assert queries[0].params == {"region": "eu-west", "max_price": 200}
+        assert queries[1].params == {"entity_key": b"\x00\xff"}
+        assert queries[2].params == {}
+
+    def test_unknown_param_raises(self, tmp_path):
+        """Should reject params that are not QueryParams fields."""
+        path = write_json(tmp_path / "set.json", {"queries": [
+            {"name": "bad", "type": "node_filter", "params": {"colour": "red"}},
+        ]})
+        with pytest.raises(ValueError, match="unknown params"):
+            load_query_set(path)
+
+    def test_unknown_type_raises(self, tmp_path):
+        """Should reject unknown query types."""
+        path = write_json(tmp_path / "set.json", {"queries": [{"name": "bad", "type": "full_scan"}]})
+        with pytest.raises(ValueError):
+            load_query_set(path)
+
+    def test_empty_set_raises(self, tmp_path):
+        """Should reject a set without queries."""
+        path = write_json(tmp_path / "set.json", {"queries": []})
+        with pytest.raises(ValueError, match="no queries"):
+            load_query_set(path)
+
+    def test_duplicate_names_raise(self, tmp_path):
+        """Should reject duplicate query names."""
+        path = write_json(tmp_path / "set.json", {"queries": [
+            {"name": "same", "type": "point_miss"},
+            {"name": "same", "type": "point_by_id"},
+        ]})
+        with pytest.raises(ValueError, match="unique"):
+            load_query_set(path)
+
+
+class TestSlo:
+    """Tests for load_slo and check_slo functions."""
+
+    def test_load_valid(self, tmp_path):
+        """Should load query type, overall and bytes_per_entity thresholds."""
+        data = {"point_by_key": {"p99": 1.0}, "overall": {"p50": 0.5}, "bytes_per_entity": 20000}
+        assert load_slo(write_json(tmp_path / "slo.json", data)) == data
+
+    @pytest.mark.parametrize("data, message", [
+        ([1, 2], "JSON object"),
+        ({"full_scan": {"p99": 1}}, "unknown SLO group"),
+        ({"overall": {"p42": 1}}, "unknown SLO stats"),
+        ({"overall": 5}, "must be an object"),
+        ({"overall": {"p99": "fast"}}, "must be numbers"),
+        ({"bytes_per_entity": {"max": 1}}, "must be a number"),
+    ])
+    def test_load_invalid_raises(self, tmp_path, data, message):
+        """Should reject malformed SLO files with a ValueError."""
+        with pytest.raises(ValueError, match=message):
+            load_slo(write_json(tmp_path / "slo.json", data))
+
+    def test_check_pass_and_fail(self):
+        """Should report each threshold with its measured value and result."""
+        slo = {"overall": {"p50": 1.0, "p99": 5.0}, "bytes_per_entity": 1000}
+        measured = {"overall": {"p50": 0.8, "p99": 7.5}, "bytes_per_entity": 900}
+
+        assert check_slo(slo, measured) == [
+            ("overall.p50", 1.0, 0.8, True),
+            ("overall.p99", 5.0, 7.5, False),
+            ("bytes_per_entity", 1000, 900, True),
+        ]
+
+    def test_check_missing_data_fails(self):
+        """Should count a threshold without measured data as a breach."""
+        checks = check_slo({"write_block": {"max": 10.0}}, {})
+        assert checks == [("write_block.max", 10.0, None, False)]
+
+
+class TestParsePragma:
+    """Tests for parse_pragma function."""
+
+    def test_parses_name_and_value(self):
+        """Should normalize the name and keep the value."""
+        assert parse_pragma(" Cache_Size = -64000") == ("cache_size", "-64000")
+        assert parse_pragma("journal_mode=WAL") == ("journal_mode", "WAL")
+
+    def test_unsupported_pragma_raises(self):
+        """Should reject pragmas that are not tunable."""
+        with pytest.raises(Exception, match="unsupported pragma"):
+            parse_pragma("foreign_keys=ON")
+
+    def test_invalid_value_raises(self):
+        """Should reject values that could inject SQL."""
+        with pytest.raises(Exception, match="invalid value"):
+            parse_pragma("cache_size=1; DROP TABLE payloads")
+
+    def test_connection_pragma_rejects_file_settings(self):
+        """Should reject pragmas that change the database file for benchmark connections."""
+        assert parse_connection_pragma("mmap_size=0") == ("mmap_size", "0")
+        for value in ["journal_mode=DELETE", "page_size=8192"]:
+            with pytest.raises(Exception, match="changes the database file"):
+                parse_connection_pragma(value)
+
+
+class TestResultVerifier:
+    """Tests for ResultVerifier class."""
+
+    @pytest.fixture
+    def conn(self, tmp_path):
+        """Database with two blocks of nodes (no workloads)."""
+        conn = init_database(str(tmp_path / "dc.db"))
+        conn.executescript(INDEX_SQL)
+        for block_data in generate_blocks(
+            num_blocks=2,
+            nodes_per_block=50,
+            workloads_per_node=0,
+            percentage_assigned=0.0,
+            payload_size=16,
+            start_block=1,
+            seed=7,
+        ):
+            for node in block_data.nodes:
+                for sql, params in node_to_sql_inserts(node, "0x00"):
+                    conn.execute(sql, params)
+        conn.commit()
+        yield conn
+        conn.close()
+
+    @pytest.fixture
+    def params(self):
+        """Node filter matching every available node."""
+        return QueryParams(current_block=2, min_cpu=0, min_ram=0, min_hours=0, max_price=10**9)
+
+    def matching_params(self, verifier, params):
+        """Params narrowed to the region and vm type of some available node."""
+        for region in REGIONS:
+            for vm_type in VM_TYPES:
+                candidate = replace(params, region=region, vm_type=vm_type)
+                if len(verifier.expected(QueryType.NODE_FILTER, candidate)) >= 2:
+                    return candidate
+        pytest.skip("no region/vm type with two available nodes")
+
+    def test_correct_result_passes(self, conn, params, tmp_path):
+        """Should accept the cheapest matches returned in price order."""
+        artifact = tmp_path / "verify.jsonl"
+        verifier = ResultVerifier(conn, max_checks=10, artifact_path=str(artifact))
+        params = self.matching_params(verifier, params)
+        expected = verifier.expected(QueryType.NODE_FILTER, params)
+        rows = [(key,) for key in sorted(expected, key=expected.get)]
+
+        assert verifier.verify(QueryType.NODE_FILTER, params, rows, limit=len(rows))
+        assert verifier.mismatches == 0
+        assert not artifact.exists()
+
+    def test_skipped_cheaper_match_fails(self, conn, params, tmp_path):
+        """Should flag a result that skips a cheaper match and record an artifact."""
+        artifact = tmp_path / "verify.jsonl"
+        verifier = ResultVerifier(conn, max_checks=10, artifact_path=str(artifact))
+        params = self.matching_params(verifier, params)
+        expected = verifier.expected(QueryType.NODE_FILTER, params)
+        by_price = sorted(expected, key=expected.get)
+        if expected[by_price[0]] == expected[by_price[-1]]:
+            pytest.skip("all matches have the same price")
+
+        assert not verifier.verify(QueryType.NODE_FILTER, params, [(by_price[-1],)], limit=1)
+        assert verifier.mismatches == 1
+        record = json.loads(artifact.read_text())
+        assert record["query_type"] == "node_filter"
+        assert any("skipped a match" in problem for problem in record["problems"])
+
+    def test_wants_respects_max_checks(self, conn, tmp_path):
+        """Should only want verifiable query types until the sample is full."""
+        verifier = ResultVerifier(conn, max_checks=1, artifact_path=str(tmp_path / "v.jsonl"))
+        assert verifier.wants(QueryType.NODE_FILTER)
+        assert not verifier.wants(QueryType.POINT_BY_KEY)
+        verifier.checked = 1
+        assert not verifier.wants(QueryType.NODE_FILTER)